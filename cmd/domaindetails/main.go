@@ -10,6 +10,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -27,6 +28,16 @@ func main() {
 	cmd.SetVersionInfo(version, commit, date)
 
 	if err := cmd.Execute(); err != nil {
+		// Commands that report their outcome through the exit code have
+		// already printed their output
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err != nil {
+				fmt.Fprintln(os.Stderr, exitErr.Err)
+			}
+			os.Exit(exitErr.Code)
+		}
+
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
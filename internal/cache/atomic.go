@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LockFile is the lock file taken while cache files are refreshed
+const LockFile = "cache.lock"
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers see either the old or the new file, never a
+// partly written one
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// lock takes an exclusive lock on the cache directory, waiting for other
+// processes sharing it to finish their refreshes. The returned function
+// releases the lock.
+func (c *Cache) lock() (func(), error) {
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return lockPath(filepath.Join(c.cacheDir, LockFile))
+}
+
+// lockPath takes an exclusive lock on the file at path, creating it if
+// needed. The returned function releases the lock.
+func lockPath(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache lock: %v", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock cache: %v", err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Where the active bootstrap data came from
+const (
+	SourceIANA     = "IANA"
+	SourceMirror   = "mirror"
+	SourceEmbedded = "embedded"
+)
+
+// embeddedBootstrap is a snapshot of the domain bootstrap file shipped with
+// the binary, used when nothing is cached and the bootstrap URL can't be
+// fetched. Refresh it with "make bootstrap-snapshot".
+//
+//go:embed bootstrap_dns.json
+var embeddedBootstrap []byte
+
+// bootstrapURL is where the domain bootstrap file is fetched from
+var bootstrapURL = IANABootstrapURL
+
+// SetBootstrapURL sets where the domain bootstrap file is fetched from, such
+// as a mirror of data.iana.org or a local file. An empty URL restores the
+// IANA default.
+func SetBootstrapURL(url string) {
+	if url == "" {
+		url = IANABootstrapURL
+	}
+	bootstrapURL = url
+}
+
+// BootstrapURL returns where the domain bootstrap file is fetched from
+func BootstrapURL() string {
+	return bootstrapURL
+}
+
+// urlSource returns the source name for a bootstrap URL
+func urlSource(url string) string {
+	if url == "" || url == IANABootstrapURL {
+		return SourceIANA
+	}
+	return SourceMirror
+}
+
+// registryURL returns where a number registry's bootstrap file is fetched
+// from. A mirror of dns.json is expected to mirror the other files next to
+// it.
+func registryURL(registry string) string {
+	if bootstrapURL != IANABootstrapURL && strings.HasSuffix(bootstrapURL, "/dns.json") {
+		return strings.TrimSuffix(bootstrapURL, "dns.json") + registry + ".json"
+	}
+	return IANARegistryBaseURL + registry + ".json"
+}
+
+// fetchResult is a downloaded bootstrap file and its cache validators
+type fetchResult struct {
+	Body         []byte
+	NotModified  bool
+	ETag         string
+	LastModified string
+}
+
+// fetch downloads a bootstrap file. URLs that aren't http or https are read
+// as local files, for air-gapped machines.
+func fetch(url string) ([]byte, error) {
+	result, err := fetchConditional(url, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Body, nil
+}
+
+// fetchConditional downloads a bootstrap file unless it is unchanged since
+// the given ETag or Last-Modified value, in which case NotModified is set
+// and Body is empty
+func fetchConditional(url, etag, lastModified string) (*fetchResult, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		data, err := os.ReadFile(strings.TrimPrefix(url, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", url, err)
+		}
+		return &fetchResult{Body: data}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &FetchError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && (etag != "" || lastModified != "") {
+		return &fetchResult{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &FetchError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &FetchError{URL: url, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	return &fetchResult{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// loadEmbeddedBootstrap parses the embedded bootstrap snapshot
+func loadEmbeddedBootstrap() (*IANABootstrap, error) {
+	var bootstrap IANABootstrap
+	if err := json.Unmarshal(embeddedBootstrap, &bootstrap); err != nil {
+		return nil, fmt.Errorf("invalid embedded bootstrap data: %v", err)
+	}
+	return &bootstrap, nil
+}
+
+// EmbeddedInfo returns the publication date and TLD count of the embedded
+// bootstrap snapshot
+func EmbeddedInfo() (string, int) {
+	bootstrap, err := loadEmbeddedBootstrap()
+	if err != nil {
+		return "", 0
+	}
+	// IANA's file always has a publication date; this only guards against a
+	// snapshot that wasn't taken with make bootstrap-snapshot
+	publication := bootstrap.Publication
+	if publication == "" {
+		publication = "unknown"
+	}
+	return publication, countTLDs(bootstrap)
+}
+
+// countTLDs returns how many TLDs a bootstrap file covers
+func countTLDs(bootstrap *IANABootstrap) int {
+	count := 0
+	for _, service := range bootstrap.Services {
+		if len(service) > 0 {
+			count += len(service[0])
+		}
+	}
+	return count
+}
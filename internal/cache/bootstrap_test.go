@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// minSnapshotTLDs is well below the number of TLDs IANA's dns.json covers,
+// so a partial or truncated snapshot is caught
+const minSnapshotTLDs = 1000
+
+func TestEmbeddedSnapshot(t *testing.T) {
+	bootstrap, err := loadEmbeddedBootstrap()
+	if err != nil {
+		t.Fatalf("embedded snapshot: %v", err)
+	}
+
+	if bootstrap.Publication == "" {
+		t.Skip("embedded snapshot is a partial seed; run make bootstrap-snapshot")
+	}
+	if _, err := time.Parse(time.RFC3339, bootstrap.Publication); err != nil {
+		t.Errorf("publication %q: %v", bootstrap.Publication, err)
+	}
+	if n := countTLDs(bootstrap); n < minSnapshotTLDs {
+		t.Errorf("embedded snapshot covers %d TLDs, want at least %d", n, minSnapshotTLDs)
+	}
+
+	publication, n := EmbeddedInfo()
+	if publication != bootstrap.Publication || n != countTLDs(bootstrap) {
+		t.Errorf("EmbeddedInfo = %q, %d", publication, n)
+	}
+}
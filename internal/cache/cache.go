@@ -2,13 +2,19 @@
 package cache
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/paths"
 )
 
 const (
@@ -18,16 +24,61 @@ const (
 	// CacheTTL is how long the cache is considered valid
 	CacheTTL = 24 * time.Hour
 
-	// CacheDir is the directory name for cache files
-	CacheDir = ".domaindetails"
-
 	// BootstrapFile is the cached bootstrap filename
 	BootstrapFile = "rdap-bootstrap.json"
 
 	// MetaFile stores cache metadata
 	MetaFile = "cache-meta.json"
+
+	// PublicSuffixListURL is the official Public Suffix List URL
+	PublicSuffixListURL = "https://publicsuffix.org/list/public_suffix_list.dat"
+
+	// PublicSuffixTTL is how long the cached Public Suffix List is
+	// considered valid
+	PublicSuffixTTL = 7 * 24 * time.Hour
+
+	// PublicSuffixFile is the cached Public Suffix List filename
+	PublicSuffixFile = "public_suffix_list.dat"
+
+	// PublicSuffixFailedFile marks a failed Public Suffix List refresh
+	PublicSuffixFailedFile = "public_suffix_list.failed"
+
+	// FetchTimeout is the timeout for downloading cached data
+	FetchTimeout = 30 * time.Second
 )
 
+// ErrReadOnly is returned when writing to a read-only cache
+var ErrReadOnly = errors.New("cache is read-only")
+
+// ErrNoRDAPServer is returned when the bootstrap data has no RDAP server for
+// a TLD
+var ErrNoRDAPServer = errors.New("no RDAP server found")
+
+// FetchError is returned when downloading cached data fails, either in
+// transport or with an unexpected HTTP status
+type FetchError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s returned status %d", e.URL, e.StatusCode)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// httpClient is used for all downloads of cached data
+var httpClient = &http.Client{Timeout: FetchTimeout}
+
+// publicSuffixURL is where the Public Suffix List is fetched from
+var publicSuffixURL = PublicSuffixListURL
+
 // IANABootstrap represents the IANA RDAP bootstrap file structure
 type IANABootstrap struct {
 	Description string       `json:"description"`
@@ -41,6 +92,15 @@ type CacheMeta struct {
 	LastUpdated time.Time `json:"lastUpdated"`
 	Version     string    `json:"version"`
 	TLDCount    int       `json:"tldCount"`
+
+	// Source and URL record where the bootstrap file was fetched from
+	Source string `json:"source,omitempty"`
+	URL    string `json:"url,omitempty"`
+
+	// ETag and LastModified are the server's validators for conditional
+	// refreshes
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
 }
 
 // CacheInfo provides information about the cache
@@ -50,59 +110,151 @@ type CacheInfo struct {
 	TLDCount    int
 	Age         time.Duration
 	IsValid     bool
+
+	// Source is where the cached bootstrap file came from (IANA or mirror)
+	// and URL the address it was fetched from
+	Source string
+	URL    string
+
+	// PublicSuffixUpdated is zero when no Public Suffix List is cached
+	PublicSuffixUpdated time.Time
+
+	// ReadOnly is set when the cache is never written to
+	ReadOnly bool
 }
 
-// Cache manages the local RDAP bootstrap cache
+// Cache manages the local RDAP bootstrap cache. It is safe for concurrent
+// use; the bootstrap data is loaded and indexed at most once per Cache, and
+// NewCache shares one Cache per directory within a process.
 type Cache struct {
 	cacheDir string
+
+	mu         sync.Mutex
+	index      bootstrapIndex
+	source     string
+	registries map[string]*IANABootstrap
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[string]*Cache)
+
+	// dirOverride is the cache directory set with SetDir
+	dirOverride string
+
+	// readOnly stops the cache from being written to
+	readOnly bool
+)
+
+// SetDir sets the cache directory, overriding DOMAINDETAILS_CACHE_DIR and
+// the XDG default. An empty dir restores the default.
+func SetDir(dir string) {
+	dirOverride = dir
+}
+
+// Dir returns the cache directory in use
+func Dir() string {
+	if dirOverride != "" {
+		return dirOverride
+	}
+	return paths.CacheDir()
 }
 
-// NewCache creates a new Cache instance
+// SetReadOnly sets whether the cache is read-only, for immutable container
+// images: cached files are used whatever their age, missing data is fetched
+// without being stored, and nothing is written
+func SetReadOnly(ro bool) {
+	readOnly = ro
+}
+
+// NewCache returns the Cache for the cache directory, shared by all callers
+// in the process. The first time the default directory is used, the
+// contents of the old ~/.domaindetails directory are moved into it.
 func NewCache() *Cache {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
+	dir := Dir()
+
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	if c, ok := caches[dir]; ok {
+		return c
 	}
 
-	return &Cache{
-		cacheDir: filepath.Join(homeDir, CacheDir),
+	if !readOnly && dirOverride == "" && paths.IsDefaultCacheDir() {
+		migrateLegacyDir(paths.LegacyCacheDir(), dir)
 	}
+
+	c := &Cache{cacheDir: dir}
+	caches[dir] = c
+	return c
 }
 
-// GetRDAPServer returns the RDAP server URL for a given TLD
+// GetRDAPServer returns the preferred RDAP server URL for a given TLD
 func (c *Cache) GetRDAPServer(tld string) (string, error) {
-	bootstrap, err := c.getBootstrap()
+	servers, err := c.GetRDAPServers(tld)
 	if err != nil {
 		return "", err
 	}
+	return servers[0], nil
+}
 
-	// Search for the TLD in services
-	for _, service := range bootstrap.Services {
-		if len(service) < 2 {
-			continue
-		}
-		tlds := service[0]
-		urls := service[1]
+// GetRDAPServers returns every RDAP server URL for a given TLD, HTTPS ones
+// first, for callers that fail over to the next server on errors
+func (c *Cache) GetRDAPServers(tld string) ([]string, error) {
+	index, err := c.getIndex()
+	if err != nil {
+		return nil, err
+	}
 
-		for _, t := range tlds {
-			if t == tld && len(urls) > 0 {
-				return urls[0], nil
-			}
-		}
+	servers := index[strings.ToLower(tld)]
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("%w for TLD: %s", ErrNoRDAPServer, tld)
 	}
+	return append([]string(nil), servers...), nil
+}
+
+// getIndex returns the indexed bootstrap data, fetching it if needed
+func (c *Cache) getIndex() (bootstrapIndex, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index != nil {
+		return c.index, nil
+	}
+
+	bootstrap, source, err := c.loadBootstrap()
+	if err != nil {
+		return nil, err
+	}
+
+	c.index = newBootstrapIndex(bootstrap)
+	c.source = source
+	return c.index, nil
+}
 
-	return "", fmt.Errorf("no RDAP server found for TLD: %s", tld)
+// Source returns where the bootstrap data in use came from: IANA, a mirror
+// or the embedded snapshot. It is empty until the data has been loaded.
+func (c *Cache) Source() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.source
 }
 
-// getBootstrap returns the cached bootstrap data, fetching if needed
-func (c *Cache) getBootstrap() (*IANABootstrap, error) {
+// loadBootstrap reads the bootstrap data from disk, refreshing it when the
+// cache is missing, expired or was fetched from another URL. The embedded
+// snapshot is used when nothing is cached and the refresh fails.
+func (c *Cache) loadBootstrap() (*IANABootstrap, string, error) {
+	if readOnly {
+		return c.loadBootstrapReadOnly()
+	}
+
 	// Check if cache exists and is valid
 	meta, err := c.getMeta()
-	if err == nil && time.Since(meta.LastUpdated) < CacheTTL {
+	if err == nil && time.Since(meta.LastUpdated) < CacheTTL && (meta.URL == "" || meta.URL == bootstrapURL) {
 		// Cache is valid, read from file
 		data, err := c.readBootstrap()
 		if err == nil {
-			return data, nil
+			return data, urlSource(meta.URL), nil
 		}
 	}
 
@@ -111,33 +263,62 @@ func (c *Cache) getBootstrap() (*IANABootstrap, error) {
 		// If update fails but we have stale cache, use it
 		data, readErr := c.readBootstrap()
 		if readErr == nil {
-			return data, nil
+			source := SourceIANA
+			if meta != nil {
+				source = urlSource(meta.URL)
+			}
+			return data, source, nil
+		}
+
+		data, embeddedErr := loadEmbeddedBootstrap()
+		if embeddedErr != nil {
+			return nil, "", err
 		}
-		return nil, fmt.Errorf("failed to fetch bootstrap data: %v", err)
+		return data, SourceEmbedded, nil
 	}
 
-	return c.readBootstrap()
+	data, err := c.readBootstrap()
+	return data, urlSource(bootstrapURL), err
 }
 
-// readBootstrap reads the cached bootstrap file
-func (c *Cache) readBootstrap() (*IANABootstrap, error) {
-	path := filepath.Join(c.cacheDir, BootstrapFile)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// loadBootstrapReadOnly uses the cached bootstrap data whatever its age,
+// fetching it without storing it when nothing is cached
+func (c *Cache) loadBootstrapReadOnly() (*IANABootstrap, string, error) {
+	if data, err := c.readBootstrap(); err == nil {
+		source := SourceIANA
+		if meta, err := c.getMeta(); err == nil {
+			source = urlSource(meta.URL)
+		}
+		return data, source, nil
 	}
 
-	var bootstrap IANABootstrap
-	if err := json.Unmarshal(data, &bootstrap); err != nil {
-		return nil, err
+	body, err := fetch(bootstrapURL)
+	if err == nil {
+		var bootstrap IANABootstrap
+		if err := json.Unmarshal(body, &bootstrap); err == nil && len(bootstrap.Services) > 0 {
+			return &bootstrap, urlSource(bootstrapURL), nil
+		}
+	}
+
+	data, err := loadEmbeddedBootstrap()
+	if err != nil {
+		return nil, "", err
 	}
+	return data, SourceEmbedded, nil
+}
 
-	return &bootstrap, nil
+// readBootstrap reads the cached bootstrap file
+func (c *Cache) readBootstrap() (*IANABootstrap, error) {
+	return readBootstrapFile(filepath.Join(c.cacheDir, BootstrapFile))
 }
 
 // getMeta reads the cache metadata
 func (c *Cache) getMeta() (*CacheMeta, error) {
-	path := filepath.Join(c.cacheDir, MetaFile)
+	return readMetaFile(filepath.Join(c.cacheDir, MetaFile))
+}
+
+// readMetaFile reads a cache metadata file
+func readMetaFile(path string) (*CacheMeta, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -151,63 +332,82 @@ func (c *Cache) getMeta() (*CacheMeta, error) {
 	return &meta, nil
 }
 
-// Update fetches fresh bootstrap data from IANA
+// Update fetches fresh bootstrap data from the bootstrap URL (IANA unless
+// a mirror is set). The download is skipped when the server reports the
+// cached copy is still current. Files are replaced atomically while holding
+// the cache lock, so concurrent processes never see a partial file.
 func (c *Cache) Update() error {
-	// Ensure cache directory exists
-	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %v", err)
+	if readOnly {
+		return ErrReadOnly
 	}
 
-	// Fetch from IANA
-	resp, err := http.Get(IANABootstrapURL)
+	unlock, err := c.lock()
 	if err != nil {
-		return fmt.Errorf("failed to fetch bootstrap data: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
+	defer unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("IANA returned status %d", resp.StatusCode)
+	url := bootstrapURL
+	bootstrapPath := filepath.Join(c.cacheDir, BootstrapFile)
+
+	// Only revalidate a cached copy fetched from the same URL
+	var etag, lastModified string
+	meta, err := c.getMeta()
+	if err == nil && (meta.URL == url || (meta.URL == "" && url == IANABootstrapURL)) {
+		if _, err := os.Stat(bootstrapPath); err == nil {
+			etag, lastModified = meta.ETag, meta.LastModified
+		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	result, err := fetchConditional(url, etag, lastModified)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
+		return fmt.Errorf("failed to fetch bootstrap data: %w", err)
+	}
+
+	if result.NotModified {
+		meta.LastUpdated = time.Now()
+		return c.writeMeta(meta)
 	}
 
 	// Parse to validate and count TLDs
 	var bootstrap IANABootstrap
-	if err := json.Unmarshal(body, &bootstrap); err != nil {
+	if err := json.Unmarshal(result.Body, &bootstrap); err != nil {
 		return fmt.Errorf("invalid bootstrap data: %v", err)
 	}
-
-	// Count TLDs
-	tldCount := 0
-	for _, service := range bootstrap.Services {
-		if len(service) > 0 {
-			tldCount += len(service[0])
-		}
+	if len(bootstrap.Services) == 0 {
+		return fmt.Errorf("invalid bootstrap data: no services")
 	}
 
 	// Write bootstrap file
-	bootstrapPath := filepath.Join(c.cacheDir, BootstrapFile)
-	if err := os.WriteFile(bootstrapPath, body, 0644); err != nil {
+	if err := writeFileAtomic(bootstrapPath, result.Body); err != nil {
 		return fmt.Errorf("failed to write bootstrap file: %v", err)
 	}
 
 	// Write metadata
-	meta := CacheMeta{
-		LastUpdated: time.Now(),
-		Version:     bootstrap.Version,
-		TLDCount:    tldCount,
-	}
+	return c.writeMeta(&CacheMeta{
+		LastUpdated:  time.Now(),
+		Version:      bootstrap.Version,
+		TLDCount:     countTLDs(&bootstrap),
+		Source:       urlSource(url),
+		URL:          url,
+		ETag:         result.ETag,
+		LastModified: result.LastModified,
+	})
+}
+
+// writeMeta writes the cache metadata
+func (c *Cache) writeMeta(meta *CacheMeta) error {
+	return writeMetaFile(filepath.Join(c.cacheDir, MetaFile), meta)
+}
 
+// writeMetaFile writes a cache metadata file
+func writeMetaFile(path string, meta *CacheMeta) error {
 	metaData, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %v", err)
 	}
 
-	metaPath := filepath.Join(c.cacheDir, MetaFile)
-	if err := os.WriteFile(metaPath, metaData, 0644); err != nil {
+	if err := writeFileAtomic(path, metaData); err != nil {
 		return fmt.Errorf("failed to write metadata: %v", err)
 	}
 
@@ -223,22 +423,118 @@ func (c *Cache) Info() (*CacheInfo, error) {
 
 	age := time.Since(meta.LastUpdated)
 
-	return &CacheInfo{
+	info := &CacheInfo{
 		Path:        c.cacheDir,
 		LastUpdated: meta.LastUpdated,
 		TLDCount:    meta.TLDCount,
 		Age:         age,
 		IsValid:     age < CacheTTL,
-	}, nil
+		Source:      urlSource(meta.URL),
+		URL:         meta.URL,
+		ReadOnly:    readOnly,
+	}
+	if info.URL == "" {
+		info.URL = IANABootstrapURL
+	}
+
+	if stat, err := os.Stat(filepath.Join(c.cacheDir, PublicSuffixFile)); err == nil {
+		info.PublicSuffixUpdated = stat.ModTime()
+	}
+
+	return info, nil
 }
 
-// Clear removes all cached data
+// Clear removes all cached data, including cached lookup results
 func (c *Cache) Clear() error {
+	if readOnly {
+		return ErrReadOnly
+	}
+
 	bootstrapPath := filepath.Join(c.cacheDir, BootstrapFile)
 	metaPath := filepath.Join(c.cacheDir, MetaFile)
 
 	os.Remove(bootstrapPath)
 	os.Remove(metaPath)
+	os.Remove(filepath.Join(c.cacheDir, PublicSuffixFile))
+	os.Remove(filepath.Join(c.cacheDir, PublicSuffixFailedFile))
+	for _, registry := range Registries {
+		os.Remove(filepath.Join(c.cacheDir, registryFile(registry)))
+		os.Remove(filepath.Join(c.cacheDir, registryMetaFile(registry)))
+	}
+	os.RemoveAll(filepath.Join(c.cacheDir, ResultsDir))
+
+	return nil
+}
+
+// GetPublicSuffixList returns the cached Public Suffix List data, fetching
+// it when missing or expired. A stale copy is returned if the refresh fails.
+// A failed refresh isn't retried for PublicSuffixTTL, so that offline hosts
+// don't wait for publicsuffix.org on every run.
+func (c *Cache) GetPublicSuffixList() ([]byte, error) {
+	path := filepath.Join(c.cacheDir, PublicSuffixFile)
+	failedPath := filepath.Join(c.cacheDir, PublicSuffixFailedFile)
+
+	if readOnly {
+		return os.ReadFile(path)
+	}
+
+	if stat, err := os.Stat(path); err == nil && time.Since(stat.ModTime()) < PublicSuffixTTL {
+		if data, err := os.ReadFile(path); err == nil {
+			return data, nil
+		}
+	}
+
+	if stat, err := os.Stat(failedPath); err == nil && time.Since(stat.ModTime()) < PublicSuffixTTL {
+		return os.ReadFile(path)
+	}
+
+	if err := c.UpdatePublicSuffixList(); err != nil {
+		writeFileAtomic(failedPath, nil)
+		if data, readErr := os.ReadFile(path); readErr == nil {
+			return data, nil
+		}
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+// UpdatePublicSuffixList fetches a fresh copy of the Public Suffix List
+func (c *Cache) UpdatePublicSuffixList() error {
+	if readOnly {
+		return ErrReadOnly
+	}
+
+	unlock, err := c.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	resp, err := httpClient.Get(publicSuffixURL)
+	if err != nil {
+		return &FetchError{URL: publicSuffixURL, Err: fmt.Errorf("failed to fetch public suffix list: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &FetchError{URL: publicSuffixURL, StatusCode: resp.StatusCode, Err: fmt.Errorf("publicsuffix.org returned status %d", resp.StatusCode)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if !bytes.Contains(body, []byte("===BEGIN ICANN DOMAINS===")) {
+		return fmt.Errorf("invalid public suffix list")
+	}
+
+	path := filepath.Join(c.cacheDir, PublicSuffixFile)
+	if err := writeFileAtomic(path, body); err != nil {
+		return fmt.Errorf("failed to write public suffix list: %v", err)
+	}
+	os.Remove(filepath.Join(c.cacheDir, PublicSuffixFailedFile))
 
 	return nil
 }
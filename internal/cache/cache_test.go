@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// servePublicSuffixList points Public Suffix List fetches at a local server
+// answering with handler, counting the requests it gets
+func servePublicSuffixList(t *testing.T, handler http.HandlerFunc) *int32 {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	saved := publicSuffixURL
+	publicSuffixURL = server.URL + "/public_suffix_list.dat"
+	t.Cleanup(func() { publicSuffixURL = saved })
+
+	return &requests
+}
+
+func TestGetPublicSuffixListBacksOffAfterFailure(t *testing.T) {
+	requests := servePublicSuffixList(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	c := &Cache{cacheDir: t.TempDir()}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetPublicSuffixList(); err == nil {
+			t.Fatal("expected an error without any copy of the list")
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("publicsuffix.org requested %d times, want 1", got)
+	}
+}
+
+func TestGetPublicSuffixListRetriesAfterBackoff(t *testing.T) {
+	list := "// ===BEGIN ICANN DOMAINS===\ncom\n"
+	requests := servePublicSuffixList(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(list))
+	})
+	c := &Cache{cacheDir: t.TempDir()}
+
+	// A failure recorded longer ago than the TTL doesn't stop a refresh
+	failedPath := filepath.Join(c.cacheDir, PublicSuffixFailedFile)
+	if err := os.WriteFile(failedPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-PublicSuffixTTL - time.Hour)
+	if err := os.Chtimes(failedPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := c.GetPublicSuffixList()
+	if err != nil {
+		t.Fatalf("GetPublicSuffixList: %v", err)
+	}
+	if string(data) != list {
+		t.Errorf("GetPublicSuffixList = %q, want %q", data, list)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("publicsuffix.org requested %d times, want 1", got)
+	}
+	if _, err := os.Stat(failedPath); !os.IsNotExist(err) {
+		t.Errorf("failure marker left after a successful refresh (err = %v)", err)
+	}
+}
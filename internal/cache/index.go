@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// bootstrapIndex maps each TLD (or other bootstrap entry) to its RDAP
+// servers, HTTPS first
+type bootstrapIndex map[string][]string
+
+// newBootstrapIndex indexes bootstrap data by entry, keeping every service
+// URL. Entries listed by more than one service get all their URLs.
+func newBootstrapIndex(bootstrap *IANABootstrap) bootstrapIndex {
+	index := make(bootstrapIndex)
+
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		for _, entry := range service[0] {
+			entry = strings.ToLower(entry)
+			for _, url := range service[1] {
+				if !containsString(index[entry], url) {
+					index[entry] = append(index[entry], url)
+				}
+			}
+		}
+	}
+
+	for _, servers := range index {
+		sortHTTPSFirst(servers)
+	}
+
+	return index
+}
+
+// sortHTTPSFirst orders server URLs HTTPS first, keeping the bootstrap's
+// order otherwise
+func sortHTTPSFirst(servers []string) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		return isHTTPS(servers[i]) && !isHTTPS(servers[j])
+	})
+}
+
+// preferredServer returns the first of a service's URLs in the order
+// sortHTTPSFirst gives, without reordering them
+func preferredServer(servers []string) string {
+	ordered := append([]string(nil), servers...)
+	sortHTTPSFirst(ordered)
+	return ordered[0]
+}
+
+// isHTTPS reports whether a server URL uses HTTPS
+func isHTTPS(url string) bool {
+	return strings.HasPrefix(strings.ToLower(url), "https://")
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
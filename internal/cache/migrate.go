@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// migrateLockFile is the lock file, next to the cache directory, taken
+// while the old cache directory is moved
+const migrateLockFile = ".domaindetails-migrate.lock"
+
+// migrateLegacyDir moves the contents of the old cache directory into dir
+// when dir doesn't exist yet. Processes starting at the same time take
+// turns, so only the first one migrates. Failures leave the old directory
+// in place; the cache is then rebuilt in dir.
+func migrateLegacyDir(legacy, dir string) {
+	if legacy == "" || legacy == dir {
+		return
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return
+	}
+	if stat, err := os.Stat(legacy); err != nil || !stat.IsDir() {
+		return
+	}
+
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return
+	}
+
+	unlock, err := lockPath(filepath.Join(parent, migrateLockFile))
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	// Another process may have migrated while we waited for the lock
+	if _, err := os.Stat(dir); err == nil {
+		return
+	}
+	if stat, err := os.Stat(legacy); err != nil || !stat.IsDir() {
+		return
+	}
+
+	if err := os.Rename(legacy, dir); err == nil {
+		return
+	}
+
+	// A rename fails across filesystems, so copy into a directory of our
+	// own and move that into place
+	tmp, err := os.MkdirTemp(parent, "."+filepath.Base(dir)+".tmp*")
+	if err != nil {
+		return
+	}
+	if err := copyDir(legacy, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		os.RemoveAll(tmp)
+		return
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		os.RemoveAll(tmp)
+		return
+	}
+	os.RemoveAll(legacy)
+}
+
+// copyDir copies the regular files and directories under src to dst
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if fi.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+// copyFile copies a single file
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
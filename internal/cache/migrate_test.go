@@ -0,0 +1,114 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newLegacyDir creates an old-style cache directory holding a few files
+func newLegacyDir(t *testing.T, root string) (string, map[string]string) {
+	t.Helper()
+
+	legacy := filepath.Join(root, "home", ".domaindetails")
+	files := map[string]string{
+		BootstrapFile: `{"services":[]}`,
+		MetaFile:      `{"url":"https://data.iana.org/rdap/dns.json"}`,
+		filepath.Join("results", "example.com.json"): `{"domain":"example.com"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(legacy, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return legacy, files
+}
+
+// checkMigrated fails unless dir holds the legacy files and the legacy
+// directory is gone
+func checkMigrated(t *testing.T, legacy, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s not migrated: %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy directory still exists (err = %v)", err)
+	}
+}
+
+func TestMigrateLegacyDir(t *testing.T) {
+	root := t.TempDir()
+	legacy, files := newLegacyDir(t, root)
+	dir := filepath.Join(root, "cache", "domaindetails")
+
+	migrateLegacyDir(legacy, dir)
+
+	checkMigrated(t, legacy, dir, files)
+}
+
+func TestMigrateLegacyDirConcurrent(t *testing.T) {
+	root := t.TempDir()
+	legacy, files := newLegacyDir(t, root)
+	dir := filepath.Join(root, "cache", "domaindetails")
+
+	// Every migration but one finds the work done; none may remove the
+	// migrated cache
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrateLegacyDir(legacy, dir)
+		}()
+	}
+	wg.Wait()
+
+	checkMigrated(t, legacy, dir, files)
+
+	entries, err := os.ReadDir(filepath.Dir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != filepath.Base(dir) && entry.Name() != migrateLockFile {
+			t.Errorf("stray entry %s left next to the cache directory", entry.Name())
+		}
+	}
+}
+
+func TestMigrateLegacyDirKeepsExistingCache(t *testing.T) {
+	root := t.TempDir()
+	legacy, _ := newLegacyDir(t, root)
+	dir := filepath.Join(root, "cache", "domaindetails")
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, BootstrapFile), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migrateLegacyDir(legacy, dir)
+
+	if got, _ := os.ReadFile(filepath.Join(dir, BootstrapFile)); string(got) != "new" {
+		t.Errorf("existing cache overwritten: %q", got)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("legacy directory removed although nothing was migrated: %v", err)
+	}
+}
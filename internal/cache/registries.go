@@ -0,0 +1,271 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number registries covered by the IANA RDAP bootstrap files besides DNS
+const (
+	RegistryIPv4 = "ipv4"
+	RegistryIPv6 = "ipv6"
+	RegistryASN  = "asn"
+)
+
+// IANARegistryBaseURL is where the IANA RDAP bootstrap files for IP
+// addresses and AS numbers are published, as <registry>.json
+const IANARegistryBaseURL = "https://data.iana.org/rdap/"
+
+// Registries lists the number registries, in the order they're updated
+var Registries = []string{RegistryIPv4, RegistryIPv6, RegistryASN}
+
+// ErrNoRegistryServer is returned when a number bootstrap has no RDAP server
+// for an address or AS number
+var ErrNoRegistryServer = errors.New("no RDAP server found")
+
+// registryFile returns the cached bootstrap filename of a number registry
+func registryFile(registry string) string {
+	return "rdap-" + registry + ".json"
+}
+
+// registryMetaFile returns the metadata filename of a number registry's
+// cached bootstrap file
+func registryMetaFile(registry string) string {
+	return "rdap-" + registry + "-meta.json"
+}
+
+// GetIPRDAPServer returns the RDAP server for an IP address or prefix,
+// using the most specific matching bootstrap entry
+func (c *Cache) GetIPRDAPServer(prefix netip.Prefix) (string, error) {
+	registry := RegistryIPv4
+	if prefix.Addr().Is6() {
+		registry = RegistryIPv6
+	}
+
+	bootstrap, err := c.getRegistry(registry)
+	if err != nil {
+		return "", err
+	}
+
+	best, bestBits := "", -1
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		for _, entry := range service[0] {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				continue
+			}
+			if p.Bits() <= prefix.Bits() && p.Bits() > bestBits && p.Contains(prefix.Addr()) {
+				best, bestBits = preferredServer(service[1]), p.Bits()
+			}
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("%w for %s", ErrNoRegistryServer, prefix)
+	}
+	return best, nil
+}
+
+// GetASNRDAPServer returns the RDAP server for an AS number, using the
+// narrowest matching bootstrap range
+func (c *Cache) GetASNRDAPServer(asn uint32) (string, error) {
+	bootstrap, err := c.getRegistry(RegistryASN)
+	if err != nil {
+		return "", err
+	}
+
+	best, bestSize := "", uint64(0)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		for _, entry := range service[0] {
+			start, end, err := parseASNRange(entry)
+			if err != nil || asn < start || asn > end {
+				continue
+			}
+			size := uint64(end-start) + 1
+			if best == "" || size < bestSize {
+				best, bestSize = preferredServer(service[1]), size
+			}
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("%w for AS%d", ErrNoRegistryServer, asn)
+	}
+	return best, nil
+}
+
+// parseASNRange parses an ASN bootstrap entry: "64512-65534" or "2043"
+func parseASNRange(entry string) (uint32, uint32, error) {
+	first, last, found := strings.Cut(entry, "-")
+
+	start, err := strconv.ParseUint(first, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return uint32(start), uint32(start), nil
+	}
+
+	end, err := strconv.ParseUint(last, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint32(start), uint32(end), nil
+}
+
+// getRegistry returns the bootstrap data of a number registry, loading it
+// at most once per Cache
+func (c *Cache) getRegistry(registry string) (*IANABootstrap, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if bootstrap, ok := c.registries[registry]; ok {
+		return bootstrap, nil
+	}
+
+	bootstrap, err := c.loadRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.registries == nil {
+		c.registries = make(map[string]*IANABootstrap)
+	}
+	c.registries[registry] = bootstrap
+	return bootstrap, nil
+}
+
+// loadRegistry reads a number registry's bootstrap data from disk,
+// refreshing it when missing or expired. A stale copy is used if the
+// refresh fails, and always in read-only mode.
+func (c *Cache) loadRegistry(registry string) (*IANABootstrap, error) {
+	path := filepath.Join(c.cacheDir, registryFile(registry))
+
+	if readOnly {
+		if bootstrap, err := readBootstrapFile(path); err == nil {
+			return bootstrap, nil
+		}
+		body, err := fetch(registryURL(registry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s bootstrap data: %w", registry, err)
+		}
+		var bootstrap IANABootstrap
+		if err := json.Unmarshal(body, &bootstrap); err != nil || len(bootstrap.Services) == 0 {
+			return nil, fmt.Errorf("invalid %s bootstrap data", registry)
+		}
+		return &bootstrap, nil
+	}
+
+	if updated := c.RegistryUpdated(registry); !updated.IsZero() && time.Since(updated) < CacheTTL {
+		if bootstrap, err := readBootstrapFile(path); err == nil {
+			return bootstrap, nil
+		}
+	}
+
+	if err := c.UpdateRegistry(registry); err != nil {
+		if bootstrap, readErr := readBootstrapFile(path); readErr == nil {
+			return bootstrap, nil
+		}
+		return nil, err
+	}
+
+	return readBootstrapFile(path)
+}
+
+// UpdateRegistry fetches fresh bootstrap data for a number registry. A
+// cached copy is revalidated with the ETag and Last-Modified the server
+// sent for it, and just marked as fresh when it hasn't changed.
+func (c *Cache) UpdateRegistry(registry string) error {
+	if readOnly {
+		return ErrReadOnly
+	}
+
+	unlock, err := c.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	url := registryURL(registry)
+	path := filepath.Join(c.cacheDir, registryFile(registry))
+	metaPath := filepath.Join(c.cacheDir, registryMetaFile(registry))
+
+	// Only revalidate a cached copy fetched from the same URL
+	var etag, lastModified string
+	meta, err := readMetaFile(metaPath)
+	if err == nil && meta.URL == url {
+		if _, err := os.Stat(path); err == nil {
+			etag, lastModified = meta.ETag, meta.LastModified
+		}
+	}
+
+	result, err := fetchConditional(url, etag, lastModified)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s bootstrap data: %w", registry, err)
+	}
+
+	if result.NotModified {
+		meta.LastUpdated = time.Now()
+		return writeMetaFile(metaPath, meta)
+	}
+
+	var bootstrap IANABootstrap
+	if err := json.Unmarshal(result.Body, &bootstrap); err != nil || len(bootstrap.Services) == 0 {
+		return fmt.Errorf("invalid %s bootstrap data", registry)
+	}
+
+	if err := writeFileAtomic(path, result.Body); err != nil {
+		return fmt.Errorf("failed to write %s bootstrap file: %v", registry, err)
+	}
+
+	return writeMetaFile(metaPath, &CacheMeta{
+		LastUpdated:  time.Now(),
+		Version:      bootstrap.Version,
+		Source:       urlSource(url),
+		URL:          url,
+		ETag:         result.ETag,
+		LastModified: result.LastModified,
+	})
+}
+
+// RegistryUpdated returns when a number registry's bootstrap data was last
+// fetched or revalidated, or zero if it isn't cached. Copies cached without
+// metadata use the file's modification time.
+func (c *Cache) RegistryUpdated(registry string) time.Time {
+	stat, err := os.Stat(filepath.Join(c.cacheDir, registryFile(registry)))
+	if err != nil {
+		return time.Time{}
+	}
+	if meta, err := readMetaFile(filepath.Join(c.cacheDir, registryMetaFile(registry))); err == nil {
+		return meta.LastUpdated
+	}
+	return stat.ModTime()
+}
+
+// readBootstrapFile reads a cached bootstrap file
+func readBootstrapFile(path string) (*IANABootstrap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var bootstrap IANABootstrap
+	if err := json.Unmarshal(data, &bootstrap); err != nil {
+		return nil, err
+	}
+
+	return &bootstrap, nil
+}
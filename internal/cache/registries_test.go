@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+)
+
+const (
+	testIPv4Bootstrap = `{"version":"1.0","services":[` +
+		`[["192.0.2.0/24"],["http://rdap.example.net/","https://rdap.example.net/"]],` +
+		`[["198.51.100.0/24"],["http://rdap.example.org/"]]]}`
+	testETag         = `"v1"`
+	testLastModified = "Mon, 01 Jan 2024 00:00:00 GMT"
+)
+
+// registryServer serves the ipv4 bootstrap file with validators, answering
+// 304 when they match, and records the conditional headers of each request
+type registryServer struct {
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func newRegistryServer(t *testing.T) *registryServer {
+	t.Helper()
+
+	s := &registryServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.headers = append(s.headers, r.Header.Clone())
+		s.mu.Unlock()
+
+		if r.URL.Path != "/ipv4.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == testETag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", testETag)
+		w.Header().Set("Last-Modified", testLastModified)
+		w.Write([]byte(testIPv4Bootstrap))
+	}))
+	t.Cleanup(server.Close)
+
+	saved := BootstrapURL()
+	SetBootstrapURL(server.URL + "/dns.json")
+	t.Cleanup(func() { SetBootstrapURL(saved) })
+
+	return s
+}
+
+// Requests returns the headers of the requests the server got
+func (s *registryServer) Requests() []http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]http.Header(nil), s.headers...)
+}
+
+func TestUpdateRegistryRevalidatesWithServerValidators(t *testing.T) {
+	server := newRegistryServer(t)
+	c := &Cache{cacheDir: t.TempDir()}
+
+	if err := c.UpdateRegistry(RegistryIPv4); err != nil {
+		t.Fatalf("first UpdateRegistry: %v", err)
+	}
+	first := c.RegistryUpdated(RegistryIPv4)
+	if first.IsZero() {
+		t.Fatal("registry not cached after an update")
+	}
+
+	if err := c.UpdateRegistry(RegistryIPv4); err != nil {
+		t.Fatalf("second UpdateRegistry: %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if h := requests[0].Get("If-None-Match") + requests[0].Get("If-Modified-Since"); h != "" {
+		t.Errorf("first request was conditional: %q", h)
+	}
+	if got := requests[1].Get("If-None-Match"); got != testETag {
+		t.Errorf("If-None-Match = %q, want %q", got, testETag)
+	}
+	if got := requests[1].Get("If-Modified-Since"); got != testLastModified {
+		t.Errorf("If-Modified-Since = %q, want the server's Last-Modified %q", got, testLastModified)
+	}
+
+	if updated := c.RegistryUpdated(RegistryIPv4); updated.Before(first) {
+		t.Errorf("RegistryUpdated went back from %s to %s after revalidation", first, updated)
+	}
+}
+
+func TestGetIPRDAPServerPrefersHTTPS(t *testing.T) {
+	newRegistryServer(t)
+	c := &Cache{cacheDir: t.TempDir()}
+
+	tests := map[string]string{
+		"192.0.2.1/32":    "https://rdap.example.net/",
+		"198.51.100.7/32": "http://rdap.example.org/",
+	}
+	for prefix, want := range tests {
+		got, err := c.GetIPRDAPServer(netip.MustParsePrefix(prefix))
+		if err != nil {
+			t.Errorf("GetIPRDAPServer(%s): %v", prefix, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("GetIPRDAPServer(%s) = %s, want %s", prefix, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// ResultsDir is the cache subdirectory holding cached lookup results, one
+// file per method and domain: results/<method>/<domain>.json
+const ResultsDir = "results"
+
+// ResultEntry is a cached lookup result
+type ResultEntry struct {
+	StoredAt  time.Time           `json:"storedAt"`
+	ExpiresAt time.Time           `json:"expiresAt"`
+	Result    *types.LookupResult `json:"result"`
+}
+
+// ResultsInfo provides information about the cached lookup results
+type ResultsInfo struct {
+	Entries int
+	Expired int
+	Size    int64
+}
+
+// resultPath returns the file a result is cached in
+func (c *Cache) resultPath(domain, method string) (string, error) {
+	domain = strings.ToLower(domain)
+	if domain == "" || strings.ContainsAny(domain, `/\`) || strings.HasPrefix(domain, ".") {
+		return "", fmt.Errorf("invalid domain for cache: %q", domain)
+	}
+	if method == "" || strings.ContainsAny(method, `/\.`) {
+		return "", fmt.Errorf("invalid method for cache: %q", method)
+	}
+
+	return filepath.Join(c.cacheDir, ResultsDir, method, domain+".json"), nil
+}
+
+// GetResult returns the cached result of a lookup if there is one that
+// hasn't expired
+func (c *Cache) GetResult(domain, method string) (*ResultEntry, bool) {
+	path, err := c.resultPath(domain, method)
+	if err != nil {
+		return nil, false
+	}
+
+	entry, err := readResultEntry(path)
+	if err != nil || time.Now().After(entry.ExpiresAt) {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// PutResult caches the result of a lookup for the given TTL
+func (c *Cache) PutResult(domain, method string, result *types.LookupResult, ttl time.Duration) error {
+	if readOnly {
+		return ErrReadOnly
+	}
+
+	path, err := c.resultPath(domain, method)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	now := time.Now()
+	entry := ResultEntry{
+		StoredAt:  now,
+		ExpiresAt: now.Add(ttl),
+		Result:    result,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %v", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write result: %v", err)
+	}
+
+	return nil
+}
+
+// ResultsInfo returns statistics about the cached lookup results
+func (c *Cache) ResultsInfo() (*ResultsInfo, error) {
+	info := &ResultsInfo{}
+	now := time.Now()
+
+	err := c.walkResults(func(path string, fi os.FileInfo) error {
+		info.Entries++
+		info.Size += fi.Size()
+
+		entry, err := readResultEntry(path)
+		if err != nil || now.After(entry.ExpiresAt) {
+			info.Expired++
+		}
+		return nil
+	})
+
+	return info, err
+}
+
+// ClearResults removes cached lookup results, or only the expired (and
+// unreadable) ones, returning how many were removed
+func (c *Cache) ClearResults(expiredOnly bool) (int, error) {
+	if readOnly {
+		return 0, ErrReadOnly
+	}
+
+	removed := 0
+	now := time.Now()
+
+	err := c.walkResults(func(path string, fi os.FileInfo) error {
+		if expiredOnly {
+			entry, err := readResultEntry(path)
+			if err == nil && !now.After(entry.ExpiresAt) {
+				return nil
+			}
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+
+	return removed, err
+}
+
+// walkResults calls fn for every cached result file
+func (c *Cache) walkResults(fn func(path string, fi os.FileInfo) error) error {
+	root := filepath.Join(c.cacheDir, ResultsDir)
+
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		return fn(path, fi)
+	})
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// readResultEntry reads a cached result file
+func readResultEntry(path string) (*ResultEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry ResultEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Result == nil {
+		return nil, fmt.Errorf("empty cache entry")
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/spf13/cobra"
+)
+
+var asnCmd = &cobra.Command{
+	Use:   "asn <number>",
+	Short: "Look up an autonomous system number",
+	Long: `Performs an RDAP lookup for an autonomous system number (with or without
+the "AS" prefix) at the regional internet registry responsible for it, found
+with the IANA asn bootstrap file (cached locally like the domain bootstrap).
+
+Examples:
+  domaindetails asn 15169
+  domaindetails asn AS13335 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runASN,
+}
+
+func init() {
+	rootCmd.AddCommand(asnCmd)
+}
+
+func runASN(cmd *cobra.Command, args []string) error {
+	asn, err := rdap.ParseASNQuery(args[0])
+	if err != nil {
+		return err
+	}
+
+	if verbose {
+		fmt.Printf("RDAP lookup for AS%d\n", asn)
+	}
+
+	result, err := newRDAPClient(verbose).LookupASN(asn)
+	if err != nil {
+		return fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+
+	printer := newPrinter()
+	return printer.PrintNetwork(result)
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/output"
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bulkConcurrency int
+	bulkColumn      string
+)
+
+var bulkCmd = &cobra.Command{
+	Use:   "bulk [file]",
+	Short: "Look up many domains concurrently from a file or stdin",
+	Long: `Reads domains from a file (or stdin when no file or "-" is given) and looks
+each one up using RDAP first, falling back to WHOIS, with a bounded pool of
+concurrent workers.
+
+Input is one domain per line; blank lines and lines starting with # are
+ignored. Files ending in .csv, or any input when --column is set, are read
+as CSV. The column may be a header name (the first row is then treated as
+a header) or a 1-based index (every row is data).
+
+Results are streamed as NDJSON, one JSON object per line, in completion
+order. Domains that fail are reported with an "error" field instead of
+aborting the run.
+
+Examples:
+  domaindetails bulk domains.txt
+  domaindetails bulk portfolio.csv --column domain --concurrency 20
+  cat domains.txt | domaindetails bulk --raw`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBulk,
+}
+
+func init() {
+	rootCmd.AddCommand(bulkCmd)
+	bulkCmd.Flags().IntVarP(&bulkConcurrency, "concurrency", "c", 10, "Number of concurrent lookups")
+	bulkCmd.Flags().StringVar(&bulkColumn, "column", "", "CSV column holding the domain (header name or 1-based index)")
+}
+
+func runBulk(cmd *cobra.Command, args []string) error {
+	if bulkConcurrency < 1 {
+		return inputError("concurrency must be at least 1")
+	}
+
+	whoisClient, err := newWhoisClient(false)
+	if err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	name := "-"
+	if len(args) == 1 && args[0] != "-" {
+		name = args[0]
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("failed to open input: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	csvMode := bulkColumn != "" || strings.HasSuffix(strings.ToLower(name), ".csv")
+
+	// Clients are shared by all workers so the bootstrap data is only
+	// loaded once for the whole run
+	rdapClient := newRDAPClient(false)
+	writer := output.NewNDJSONWriter(os.Stdout, rawOutput)
+
+	domains := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(domains)
+		readErr <- readDomains(in, csvMode, bulkColumn, domains)
+	}()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		total  int
+		failed int
+	)
+
+	for i := 0; i < bulkConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for domain := range domains {
+				result := bulkLookup(rdapClient, whoisClient, domain)
+
+				mu.Lock()
+				total++
+				if result.Error != "" {
+					failed++
+				}
+				mu.Unlock()
+
+				if err := writer.Write(result); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", domain, err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Processed %d domains (%d failed)\n", total, failed)
+	}
+
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("failed to read input: %v", err)
+	}
+
+	return nil
+}
+
+// bulkLookup looks up a single domain, recording any failure in the result
+func bulkLookup(rdapClient *rdap.Client, whoisClient whoisClient, input string) *types.LookupResult {
+	query, err := normalizeDomain(input)
+	if err != nil {
+		return &types.LookupResult{
+			Domain: strings.ToLower(strings.TrimSpace(input)),
+			Error:  err.Error(),
+		}
+	}
+	domain := query.Domain
+
+	result, err := cachedLookup("lookup", domain, func() (*types.LookupResult, error) {
+		return lookupDomain(rdapClient, whoisClient, domain, false)
+	})
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", domain, err)
+		}
+		return &types.LookupResult{
+			Domain: domain,
+			Error:  err.Error(),
+		}
+	}
+	query.annotate(result)
+
+	return result
+}
+
+// readDomains reads domains from r and sends them to out, either one per
+// line or from a CSV column
+func readDomains(r io.Reader, csvMode bool, column string, out chan<- string) error {
+	if !csvMode {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			out <- line
+		}
+		return scanner.Err()
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	// Resolve the column: a number is a 1-based index, anything else is a
+	// header name looked up in the first row
+	index := 0
+	header := false
+	if column != "" {
+		n, err := strconv.Atoi(column)
+		if err == nil {
+			if n < 1 {
+				return inputError("invalid column index: %d", n)
+			}
+			index = n - 1
+		} else {
+			header = true
+		}
+	}
+
+	if header {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		index = -1
+		for i, name := range row {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return inputError("column %q not found in CSV header", column)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if index >= len(row) {
+			continue
+		}
+		if value := strings.TrimSpace(row[index]); value != "" {
+			out <- value
+		}
+	}
+}
@@ -9,25 +9,53 @@ import (
 
 var cacheCmd = &cobra.Command{
 	Use:   "cache",
-	Short: "Manage the local RDAP bootstrap cache",
-	Long: `Manage the local cache of IANA RDAP bootstrap data.
+	Short: "Manage the local RDAP bootstrap and results cache",
+	Long: `Manage the local cache of IANA RDAP bootstrap data and lookup results.
 
-The CLI caches the RDAP bootstrap file from data.iana.org to avoid
-repeated network requests. The cache is stored in ~/.domaindetails/
+The CLI caches the RDAP bootstrap files from data.iana.org (domains, and
+IPv4, IPv6 and AS numbers for the ip and asn commands) to avoid repeated
+network requests. The cache is stored in domaindetails/ under
+$XDG_CACHE_HOME (or the platform's cache directory), unless set with
+--cache-dir or DOMAINDETAILS_CACHE_DIR. An existing ~/.domaindetails/ is
+moved there on first use. With --cache-read-only (or
+DOMAINDETAILS_CACHE_READONLY=true) cached files are used whatever their age
+and nothing is written.
+
+The bootstrap file can be fetched from a mirror or a local file with
+--bootstrap-url or DOMAINDETAILS_BOOTSTRAP_URL; IP and AS number files are
+then fetched from next to it. When nothing is cached and the bootstrap file
+can't be fetched, a snapshot embedded in the binary is used.
+
+Lookup results are only cached when a TTL is set with --cache-ttl or
+DOMAINDETAILS_CACHE_TTL, and are stored in the results/ subdirectory
 
 Examples:
-  domaindetails cache update    # Force update the cache
-  domaindetails cache info      # Show cache status
-  domaindetails cache clear     # Clear the cache`,
+  domaindetails cache update             # Force update the cache
+  domaindetails cache info               # Show cache status
+  domaindetails cache clear              # Clear the cache
+  domaindetails cache clear --expired    # Remove expired results only`,
 }
 
+var (
+	clearResults bool
+	clearExpired bool
+)
+
 var cacheUpdateCmd = &cobra.Command{
 	Use:   "update",
-	Short: "Force update the RDAP bootstrap cache",
+	Short: "Force update the RDAP bootstrap files and Public Suffix List",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := cache.NewCache()
 		if err := c.Update(); err != nil {
-			return fmt.Errorf("failed to update cache: %v", err)
+			return fmt.Errorf("failed to update cache: %w", err)
+		}
+		if err := c.UpdatePublicSuffixList(); err != nil {
+			return fmt.Errorf("failed to update cache: %w", err)
+		}
+		for _, registry := range cache.Registries {
+			if err := c.UpdateRegistry(registry); err != nil {
+				return fmt.Errorf("failed to update cache: %w", err)
+			}
 		}
 		fmt.Println("Cache updated successfully")
 		return nil
@@ -41,14 +69,44 @@ var cacheInfoCmd = &cobra.Command{
 		c := cache.NewCache()
 		info, err := c.Info()
 		if err != nil {
-			return fmt.Errorf("failed to get cache info: %v", err)
+			fmt.Printf("Cache directory: %s\n", cache.Dir())
+			fmt.Printf("Bootstrap cache: %v\n", err)
+			publication, tlds := cache.EmbeddedInfo()
+			fmt.Printf("Source:          %s snapshot (published %s, %d TLDs)\n", cache.SourceEmbedded, publication, tlds)
+			fmt.Printf("Bootstrap URL:   %s\n", cache.BootstrapURL())
+		} else {
+			fmt.Printf("Cache directory: %s\n", info.Path)
+			fmt.Printf("Source:          %s\n", info.Source)
+			fmt.Printf("Bootstrap URL:   %s\n", info.URL)
+			if info.URL != cache.BootstrapURL() {
+				fmt.Printf("                 (refetched from %s on next lookup)\n", cache.BootstrapURL())
+			}
+			fmt.Printf("Last updated:    %s\n", info.LastUpdated.Format("2006-01-02 15:04:05"))
+			fmt.Printf("TLDs cached:     %d\n", info.TLDCount)
+			fmt.Printf("Cache age:       %s\n", info.Age.Round(1).String())
+			fmt.Printf("Cache valid:     %v\n", info.IsValid)
+			if info.ReadOnly {
+				fmt.Printf("Read-only:       true\n")
+			}
+			if info.PublicSuffixUpdated.IsZero() {
+				fmt.Printf("Public suffixes: embedded snapshot\n")
+			} else {
+				fmt.Printf("Public suffixes: updated %s\n", info.PublicSuffixUpdated.Format("2006-01-02 15:04:05"))
+			}
+		}
+
+		for _, registry := range cache.Registries {
+			if updated := c.RegistryUpdated(registry); !updated.IsZero() {
+				fmt.Printf("%-16s updated %s\n", registry+" bootstrap:", updated.Format("2006-01-02 15:04:05"))
+			}
+		}
+
+		results, err := c.ResultsInfo()
+		if err != nil {
+			return fmt.Errorf("failed to get results cache info: %v", err)
 		}
 
-		fmt.Printf("Cache directory: %s\n", info.Path)
-		fmt.Printf("Last updated:    %s\n", info.LastUpdated.Format("2006-01-02 15:04:05"))
-		fmt.Printf("TLDs cached:     %d\n", info.TLDCount)
-		fmt.Printf("Cache age:       %s\n", info.Age.Round(1).String())
-		fmt.Printf("Cache valid:     %v\n", info.IsValid)
+		fmt.Printf("Cached results:  %d (%d expired, %d bytes)\n", results.Entries, results.Expired, results.Size)
 
 		return nil
 	},
@@ -59,8 +117,18 @@ var cacheClearCmd = &cobra.Command{
 	Short: "Clear the local cache",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := cache.NewCache()
+
+		if clearResults || clearExpired {
+			removed, err := c.ClearResults(clearExpired)
+			if err != nil {
+				return fmt.Errorf("failed to clear cached results: %w", err)
+			}
+			fmt.Printf("Removed %d cached results\n", removed)
+			return nil
+		}
+
 		if err := c.Clear(); err != nil {
-			return fmt.Errorf("failed to clear cache: %v", err)
+			return fmt.Errorf("failed to clear cache: %w", err)
 		}
 		fmt.Println("Cache cleared successfully")
 		return nil
@@ -72,4 +140,6 @@ func init() {
 	cacheCmd.AddCommand(cacheUpdateCmd)
 	cacheCmd.AddCommand(cacheInfoCmd)
 	cacheCmd.AddCommand(cacheClearCmd)
+	cacheClearCmd.Flags().BoolVar(&clearResults, "results", false, "Only clear cached lookup results")
+	cacheClearCmd.Flags().BoolVar(&clearExpired, "expired", false, "Only clear expired cached lookup results")
 }
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/config"
+	"github.com/simplebytes-com/domaindetails-cli/internal/paths"
+	"github.com/spf13/cobra"
+)
+
+// ProfileEnv is the environment variable that selects the configuration
+// profile
+const ProfileEnv = "DOMAINDETAILS_PROFILE"
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage configuration profiles",
+	Long: `Manage the configuration file and its named profiles.
+
+Profiles hold default settings, so that for example a team can share one
+profile for CI and another for interactive use. The profile is selected with
+--profile or DOMAINDETAILS_PROFILE, else the file's default_profile, else the
+profile named "default". Command-line flags override profile settings.
+
+Settings:
+  output         text, json or table (table only applies to expiry)
+  timeout        timeout for each RDAP and WHOIS request, e.g. 30s
+  whois_source   api or native
+  api_base_url   DomainDetails.com API base URL
+  proxy          HTTP proxy URL
+  concurrency    concurrent lookups for bulk and expiry
+  user_agent     User-Agent sent with RDAP and API requests
+  cache_ttl      results cache TTL, e.g. 1h
+  bootstrap_url  URL or absolute path of the IANA RDAP bootstrap file
+  cache_dir      cache directory (an absolute path)
+
+The file is config.yaml under $XDG_CONFIG_HOME/domaindetails (or the
+platform's config directory), unless DOMAINDETAILS_CONFIG_DIR is set.
+
+Examples:
+  domaindetails config set output json --profile ci
+  domaindetails config set default_profile ci
+  domaindetails config get timeout --profile ci
+  domaindetails config list
+  domaindetails config path`,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show the configuration file path",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the profiles and their settings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		if len(cfg.Profiles) == 0 {
+			fmt.Println("No profiles configured")
+			return nil
+		}
+
+		active := cfg.ProfileName(profileName)
+		for i, name := range cfg.ProfileNames() {
+			if i > 0 {
+				fmt.Println()
+			}
+			marker := ""
+			if name == active {
+				marker = " (active)"
+			}
+			fmt.Printf("[%s]%s\n", name, marker)
+
+			profile, _ := cfg.Profile(name)
+			for _, key := range config.Keys {
+				if value, _ := profile.Get(key); value != "" {
+					fmt.Printf("  %-13s %s\n", key, value)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Show a setting of the selected profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "default_profile" {
+			fmt.Println(cfg.DefaultProfile)
+			return nil
+		}
+
+		name := cfg.ProfileName(profileName)
+		profile, ok := cfg.Profile(name)
+		if !ok {
+			profile = &config.Profile{}
+		}
+		value, err := profile.Get(args[0])
+		if err != nil {
+			return &InputError{Err: err}
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting of the selected profile (an empty value unsets it)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		key, value := args[0], args[1]
+		if key == "default_profile" {
+			cfg.DefaultProfile = value
+		} else if err := cfg.Set(cfg.ProfileName(profileName), key, value); err != nil {
+			return &InputError{Err: err}
+		}
+
+		return cfg.Save(path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+}
+
+// loadConfig reads the configuration file
+func loadConfig() (string, *config.Config, error) {
+	path, err := config.Path()
+	if err != nil {
+		return "", nil, err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return "", nil, &InputError{Err: err}
+	}
+	return path, cfg, nil
+}
+
+// applyProfile sets the flags the selected profile configures, unless they
+// were given on the command line. Environment variables for a setting take
+// precedence over the profile. The config commands themselves don't apply
+// profiles, so a broken profile can still be fixed.
+func applyProfile(cmd *cobra.Command) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd {
+			return nil
+		}
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		// Without a config directory there is no config file to read
+		return nil
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return &InputError{Err: err}
+	}
+
+	name := cfg.ProfileName(profileName)
+	profile, ok := cfg.Profile(name)
+	if !ok {
+		// Only a profile that was asked for has to exist
+		if name != config.DefaultProfileName {
+			return inputError("profile %q not found in %s", name, path)
+		}
+		return nil
+	}
+
+	set := func(flag, value string) error {
+		f := cmd.Flags().Lookup(flag)
+		if value == "" || f == nil || f.Changed {
+			return nil
+		}
+		if err := f.Value.Set(value); err != nil {
+			return inputError("profile %s: invalid %s %q: %v", name, flag, value, err)
+		}
+		return nil
+	}
+
+	// Profile settings and the flags they set. Output applies to --json,
+	// and to --format where a command has one.
+	settings := [][2]string{
+		{"timeout", profile.Timeout},
+		{"whois-source", profile.WhoisSource},
+		{"api-url", profile.APIBaseURL},
+		{"proxy", profile.Proxy},
+		{"user-agent", profile.UserAgent},
+		{"format", profile.Output},
+	}
+	if profile.Output == "json" {
+		settings = append(settings, [2]string{"json", "true"})
+	}
+	if profile.Concurrency > 0 {
+		settings = append(settings, [2]string{"concurrency", strconv.Itoa(profile.Concurrency)})
+	}
+	if os.Getenv(ResultTTLEnv) == "" {
+		settings = append(settings, [2]string{"cache-ttl", profile.CacheTTL})
+	}
+	if os.Getenv(BootstrapURLEnv) == "" {
+		settings = append(settings, [2]string{"bootstrap-url", profile.BootstrapURL})
+	}
+	if os.Getenv(paths.CacheDirEnv) == "" {
+		settings = append(settings, [2]string{"cache-dir", profile.CacheDir})
+	}
+
+	for _, setting := range settings {
+		if err := set(setting[0], setting[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setProxy sends all HTTP requests through a proxy
+func setProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return inputError("invalid proxy URL: %s", proxy)
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("can't set proxy")
+	}
+	transport.Proxy = http.ProxyURL(u)
+	return nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/simplebytes-com/domaindetails-cli/internal/psl"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+var (
+	pslOnce sync.Once
+	pslList *psl.List
+)
+
+// domainQuery is a validated domain ready for lookup
+type domainQuery struct {
+	// Domain is the domain to look up
+	Domain string
+
+	// Input is the domain as given, and Stripped the subdomain labels
+	// removed from it (empty unless --strip-subdomains removed any)
+	Input    string
+	Stripped string
+}
+
+// publicSuffixes returns the Public Suffix List, loading it once
+func publicSuffixes() *psl.List {
+	pslOnce.Do(func() {
+		pslList = psl.Load(cache.NewCache())
+	})
+	return pslList
+}
+
+// normalizeDomain validates a domain given on the command line and, with
+// --strip-subdomains, reduces it to its registrable domain
+func normalizeDomain(input string) (*domainQuery, error) {
+	input = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(input)), ".")
+
+	// Internationalized names are looked up by their A-labels
+	domain, err := idn.ToASCII(input)
+	if err != nil {
+		return nil, inputError("invalid domain format: %s: %v", input, err)
+	}
+
+	if !isValidDomain(domain) {
+		return nil, inputError("invalid domain format: %s", input)
+	}
+
+	query := &domainQuery{Domain: domain, Input: input}
+
+	if stripSubdomains {
+		registrable, err := publicSuffixes().RegistrableDomain(domain)
+		if err != nil {
+			return nil, inputError("invalid domain: %v", err)
+		}
+		if registrable != domain {
+			query.Domain = registrable
+			query.Stripped = strings.TrimSuffix(domain, "."+registrable)
+		}
+	}
+
+	return query, nil
+}
+
+// annotate records on the result the Unicode form of the domain and which
+// subdomain, if any, was stripped
+func (q *domainQuery) annotate(result *types.LookupResult) {
+	result.UnicodeDomain = idn.UnicodeName(result.Domain)
+
+	if q.Stripped != "" {
+		result.Query = q.Input
+		result.StrippedSubdomain = q.Stripped
+	}
+}
+
+// isValidDomain checks that an ASCII domain follows the LDH rules: letters,
+// digits and inner hyphens, with "--" in the third and fourth position only
+// allowed for A-labels ("xn--")
+func isValidDomain(domain string) bool {
+	if len(domain) < 3 || len(domain) > 253 {
+		return false
+	}
+
+	parts := strings.Split(domain, ".")
+	if len(parts) < 2 {
+		return false
+	}
+
+	for _, part := range parts {
+		if len(part) == 0 || len(part) > 63 {
+			return false
+		}
+		// Check for valid characters
+		for i, c := range part {
+			if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || (c == '-' && i > 0 && i < len(part)-1)) {
+				return false
+			}
+		}
+		// Reserved LDH labels ("ab--") must be A-labels
+		if len(part) >= 4 && part[2:4] == "--" && !strings.HasPrefix(part, "xn--") {
+			return false
+		}
+	}
+
+	// The TLD can't be all-numeric
+	tld := parts[len(parts)-1]
+	if strings.Trim(tld, "0123456789") == "" {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var entityServer string
+
+var entityCmd = &cobra.Command{
+	Use:   "entity <handle>",
+	Short: "Look up an RDAP entity (contact, registrar, organization) by handle",
+	Long: `Performs an RDAP lookup for an entity handle, such as a registrar, contact
+or organization, on the given RDAP server. Entity handles aren't covered by
+the bootstrap files, so the server must be given with --server (the server
+that returned the handle, e.g. from "rdap --raw" output).
+
+Shows the entity's decoded contact details and the networks, autonomous
+systems and domains linked to it.
+
+Examples:
+  domaindetails entity GOGL --server https://rdap.arin.net/registry/
+  domaindetails entity 292 --server https://rdap.verisign.com/com/v1/ --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEntity,
+}
+
+func init() {
+	rootCmd.AddCommand(entityCmd)
+	entityCmd.Flags().StringVar(&entityServer, "server", "", "RDAP server base URL to query (required)")
+	entityCmd.MarkFlagRequired("server")
+}
+
+func runEntity(cmd *cobra.Command, args []string) error {
+	handle := strings.TrimSpace(args[0])
+	if handle == "" {
+		return inputError("invalid entity handle")
+	}
+
+	if verbose {
+		fmt.Printf("RDAP lookup for entity: %s\n", handle)
+	}
+
+	result, err := newRDAPClient(verbose).LookupEntity(handle, entityServer)
+	if err != nil {
+		return fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+
+	printer := newPrinter()
+	return printer.PrintEntity(result)
+}
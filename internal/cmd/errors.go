@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+	"github.com/simplebytes-com/domaindetails-cli/internal/whois"
+)
+
+// Exit codes of failed commands. The expiry command uses the Nagios codes
+// instead.
+const (
+	ExitFailure      = 1 // any other error
+	ExitInvalidInput = 2 // invalid arguments, flags or configuration
+	ExitUnsupported  = 3 // no RDAP or WHOIS server for the query, or search not supported
+	ExitNotFound     = 4 // the object doesn't exist
+	ExitNetwork      = 5 // the server couldn't be reached
+	ExitTimeout      = 6 // the server didn't answer in time
+	ExitRateLimited  = 7 // the server kept rate limiting requests
+	ExitServerError  = 8 // the server answered with an error status
+	ExitCacheError   = 9 // the cache couldn't be read, written or refreshed
+)
+
+// Error codes reported in JSON error output
+const (
+	codeFailure      = "error"
+	codeInvalidInput = "invalid_input"
+	codeUnsupported  = "unsupported"
+	codeNotFound     = "not_found"
+	codeNetwork      = "network_error"
+	codeTimeout      = "timeout"
+	codeRateLimited  = "rate_limited"
+	codeServerError  = "server_error"
+	codeCacheError   = "cache_error"
+)
+
+// InputError is returned for invalid arguments, flags or configuration
+type InputError struct {
+	Err error
+}
+
+func (e *InputError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// inputError returns an InputError with a formatted message
+func inputError(format string, args ...interface{}) error {
+	return &InputError{Err: fmt.Errorf(format, args...)}
+}
+
+// LookupError is returned when an RDAP lookup and its WHOIS fallback both
+// fail. It unwraps to both errors, so the failure is reported by the more
+// telling one; a missing RDAP or WHOIS server only counts when the other
+// lookup didn't fail for another reason.
+type LookupError struct {
+	RDAP  error
+	WHOIS error
+}
+
+func (e *LookupError) Error() string {
+	return fmt.Sprintf("lookup failed: RDAP: %v; WHOIS: %v", e.RDAP, e.WHOIS)
+}
+
+func (e *LookupError) Unwrap() []error {
+	switch {
+	case isNoServer(e.RDAP) && !isNoServer(e.WHOIS):
+		return []error{e.WHOIS}
+	case isNoServer(e.WHOIS) && !isNoServer(e.RDAP):
+		return []error{e.RDAP}
+	}
+	return []error{e.RDAP, e.WHOIS}
+}
+
+// isNoServer reports whether err says there is no server for the query
+func isNoServer(err error) bool {
+	return errors.Is(err, cache.ErrNoRDAPServer) || errors.Is(err, whois.ErrNoWhoisServer)
+}
+
+// classifyError describes an error for output, mapping it to its error code
+// and exit code
+func classifyError(err error) *types.ErrorResult {
+	result := &types.ErrorResult{Code: codeFailure, Message: err.Error(), ExitCode: ExitFailure}
+	set := func(code string, exitCode int) {
+		result.Code, result.ExitCode = code, exitCode
+	}
+
+	var (
+		inputErr     *InputError
+		queryErr     *rdap.QueryError
+		notFoundErr  *rdap.NotFoundError
+		rdapStatus   *rdap.StatusError
+		rdapRequest  *rdap.RequestError
+		whoisStatus  *whois.StatusError
+		whoisRequest *whois.RequestError
+		fetchErr     *cache.FetchError
+		timeoutErr   interface{ Timeout() bool }
+	)
+
+	switch {
+	case errors.As(err, &inputErr), errors.As(err, &queryErr):
+		set(codeInvalidInput, ExitInvalidInput)
+	case errors.Is(err, cache.ErrNoRDAPServer), errors.Is(err, cache.ErrNoRegistryServer),
+		errors.Is(err, rdap.ErrSearchUnsupported):
+		set(codeUnsupported, ExitUnsupported)
+		result.Method = "rdap"
+	case errors.Is(err, whois.ErrNoWhoisServer):
+		set(codeUnsupported, ExitUnsupported)
+		result.Method = "whois"
+	case errors.As(err, &notFoundErr):
+		set(codeNotFound, ExitNotFound)
+		result.Method, result.Server = "rdap", notFoundErr.Server
+		result.HTTPStatus = http.StatusNotFound
+	case errors.As(err, &rdapStatus):
+		setStatus(result, rdapStatus.StatusCode)
+		result.Method, result.Server = "rdap", rdapStatus.Server
+	case errors.As(err, &whoisStatus):
+		setStatus(result, whoisStatus.StatusCode)
+		result.Method, result.Server = "whois", whoisStatus.Server
+	case errors.Is(err, cache.ErrReadOnly):
+		set(codeCacheError, ExitCacheError)
+	case errors.As(err, &fetchErr):
+		set(codeCacheError, ExitCacheError)
+		result.Method, result.Server = "bootstrap", urlHost(fetchErr.URL)
+		result.HTTPStatus = fetchErr.StatusCode
+	case errors.As(err, &rdapRequest):
+		set(codeNetwork, ExitNetwork)
+		if rdapRequest.Timeout() {
+			set(codeTimeout, ExitTimeout)
+		}
+		result.Method, result.Server = "rdap", rdapRequest.Server
+	case errors.As(err, &whoisRequest):
+		set(codeNetwork, ExitNetwork)
+		if whoisRequest.Timeout() {
+			set(codeTimeout, ExitTimeout)
+		}
+		result.Method, result.Server = "whois", whoisRequest.Server
+	case errors.As(err, &timeoutErr) && timeoutErr.Timeout():
+		set(codeTimeout, ExitTimeout)
+	}
+
+	return result
+}
+
+// setStatus sets the error and exit codes for an HTTP error status
+func setStatus(result *types.ErrorResult, status int) {
+	result.HTTPStatus = status
+	switch {
+	case status == http.StatusTooManyRequests:
+		result.Code, result.ExitCode = codeRateLimited, ExitRateLimited
+	case status == http.StatusNotFound:
+		result.Code, result.ExitCode = codeNotFound, ExitNotFound
+	default:
+		result.Code, result.ExitCode = codeServerError, ExitServerError
+	}
+}
+
+// urlHost returns the host of a URL, or the URL itself for local files
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/dates"
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/spf13/cobra"
+)
+
+// Nagios plugin exit codes, used by the expiry command
+const (
+	ExitOK       = 0
+	ExitWarning  = 1
+	ExitCritical = 2
+	ExitUnknown  = 3
+)
+
+// Expiry states, in the order they're reported
+const (
+	stateOK       = "OK"
+	stateWarning  = "WARNING"
+	stateCritical = "CRITICAL"
+	stateUnknown  = "UNKNOWN"
+)
+
+var (
+	expiryWarning     int
+	expiryCritical    int
+	expiryFile        string
+	expiryFormat      string
+	expiryConcurrency int
+)
+
+// expiryCheck is the expiry state of a single domain
+type expiryCheck struct {
+	Domain         string `json:"domain"`
+	Status         string `json:"status"`
+	DaysLeft       *int   `json:"daysLeft,omitempty"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+	Method         string `json:"method,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// expiryReport is the JSON output of the expiry command
+type expiryReport struct {
+	Status       string         `json:"status"`
+	ExitCode     int            `json:"exitCode"`
+	WarningDays  int            `json:"warningDays"`
+	CriticalDays int            `json:"criticalDays"`
+	Domains      []*expiryCheck `json:"domains"`
+}
+
+var expiryCmd = &cobra.Command{
+	Use:   "expiry [domains...]",
+	Short: "Check how many days are left until domains expire",
+	Long: `Looks up each domain (RDAP first, falling back to WHOIS) and reports the
+number of days until it expires, flagging domains under the warning and
+critical thresholds.
+
+Domains are taken from the arguments and from --file (one per line, or a
+CSV file as accepted by bulk; "-" reads stdin).
+
+The exit code follows the Nagios plugin convention, so the command can be
+used directly as a monitoring check or in cron jobs:
+  0  OK        all domains expire after the warning threshold
+  1  WARNING   a domain expires within the warning threshold
+  2  CRITICAL  a domain expires within the critical threshold, or has expired
+  3  UNKNOWN   a domain couldn't be checked (lookup failed, not registered,
+               or no expiration date), and none is WARNING or CRITICAL
+
+Examples:
+  domaindetails expiry example.com example.org
+  domaindetails expiry --file domains.txt --warning 60 --critical 14
+  domaindetails expiry --file domains.txt --format table
+  domaindetails expiry example.com --json`,
+	RunE:          runExpiry,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	rootCmd.AddCommand(expiryCmd)
+	expiryCmd.Flags().IntVarP(&expiryWarning, "warning", "w", 30, "Warn when a domain expires within this many days")
+	expiryCmd.Flags().IntVarP(&expiryCritical, "critical", "c", 7, "Critical when a domain expires within this many days")
+	expiryCmd.Flags().StringVarP(&expiryFile, "file", "f", "", "Read domains from a file (\"-\" for stdin)")
+	expiryCmd.Flags().StringVar(&expiryFormat, "format", "text", "Output format (text, json, table)")
+	expiryCmd.Flags().IntVar(&expiryConcurrency, "concurrency", 10, "Number of concurrent lookups")
+}
+
+func runExpiry(cmd *cobra.Command, args []string) error {
+	format := expiryFormat
+	if jsonOutput {
+		format = "json"
+	}
+	if format != "text" && format != "json" && format != "table" {
+		return &ExitError{Code: ExitUnknown, Err: inputError("invalid format %q (expected text, json or table)", format)}
+	}
+	if expiryCritical > expiryWarning {
+		return &ExitError{Code: ExitUnknown, Err: inputError("critical threshold (%d) must not exceed warning threshold (%d)", expiryCritical, expiryWarning)}
+	}
+	if expiryConcurrency < 1 {
+		return &ExitError{Code: ExitUnknown, Err: inputError("concurrency must be at least 1")}
+	}
+
+	domains, err := expiryDomains(args)
+	if err != nil {
+		return &ExitError{Code: ExitUnknown, Err: err}
+	}
+	if len(domains) == 0 {
+		return &ExitError{Code: ExitUnknown, Err: inputError("no domains given")}
+	}
+
+	whoisClient, err := newWhoisClient(false)
+	if err != nil {
+		return &ExitError{Code: ExitUnknown, Err: err}
+	}
+	rdapClient := newRDAPClient(false)
+
+	// Checks keep the input order, whatever order lookups complete in
+	checks := make([]*expiryCheck, len(domains))
+	indexes := make(chan int)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < expiryConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				checks[i] = checkExpiry(rdapClient, whoisClient, domains[i], now)
+			}
+		}()
+	}
+	for i := range domains {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report := &expiryReport{
+		WarningDays:  expiryWarning,
+		CriticalDays: expiryCritical,
+		Domains:      checks,
+	}
+	report.Status, report.ExitCode = overallExpiryState(checks)
+
+	switch format {
+	case "json":
+		err = printExpiryJSON(os.Stdout, report)
+	case "table":
+		err = printExpiryTable(os.Stdout, report)
+	default:
+		err = printExpiryText(os.Stdout, report)
+	}
+	if err != nil {
+		return &ExitError{Code: ExitUnknown, Err: err}
+	}
+
+	if report.ExitCode != ExitOK {
+		return &ExitError{Code: report.ExitCode}
+	}
+	return nil
+}
+
+// expiryDomains collects the domains to check from the arguments and --file
+func expiryDomains(args []string) ([]string, error) {
+	domains := append([]string(nil), args...)
+	if expiryFile == "" {
+		return domains, nil
+	}
+
+	in := io.Reader(os.Stdin)
+	if expiryFile != "-" {
+		f, err := os.Open(expiryFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	out := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(out)
+		readErr <- readDomains(in, strings.HasSuffix(strings.ToLower(expiryFile), ".csv"), "", out)
+	}()
+	for domain := range out {
+		domains = append(domains, domain)
+	}
+
+	if err := <-readErr; err != nil {
+		return nil, fmt.Errorf("failed to read input: %v", err)
+	}
+	return domains, nil
+}
+
+// checkExpiry looks up a domain and classifies how soon it expires
+func checkExpiry(rdapClient *rdap.Client, whoisClient whoisClient, input string, now time.Time) *expiryCheck {
+	result := bulkLookup(rdapClient, whoisClient, input)
+	check := &expiryCheck{
+		Domain: result.Domain,
+		Status: stateUnknown,
+		Method: result.Method,
+	}
+
+	switch {
+	case result.Error != "":
+		check.Error = result.Error
+		return check
+	case result.Available:
+		check.Error = "domain is not registered"
+		return check
+	case result.Parsed == nil || result.Parsed.ExpirationDate == "":
+		check.Error = "no expiration date found"
+		return check
+	}
+
+	check.ExpirationDate = result.Parsed.ExpirationDate
+	expires, err := dates.Parse(check.ExpirationDate)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	days := int(math.Floor(expires.Sub(now).Hours() / 24))
+	check.DaysLeft = &days
+
+	switch {
+	case days <= expiryCritical:
+		check.Status = stateCritical
+	case days <= expiryWarning:
+		check.Status = stateWarning
+	default:
+		check.Status = stateOK
+	}
+	return check
+}
+
+// overallExpiryState returns the worst state of all checks and its exit
+// code: CRITICAL, then WARNING, then UNKNOWN
+func overallExpiryState(checks []*expiryCheck) (string, int) {
+	counts := expiryCounts(checks)
+	switch {
+	case counts[stateCritical] > 0:
+		return stateCritical, ExitCritical
+	case counts[stateWarning] > 0:
+		return stateWarning, ExitWarning
+	case counts[stateUnknown] > 0:
+		return stateUnknown, ExitUnknown
+	}
+	return stateOK, ExitOK
+}
+
+func expiryCounts(checks []*expiryCheck) map[string]int {
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[check.Status]++
+	}
+	return counts
+}
+
+// expirySummary describes a check in words
+func expirySummary(check *expiryCheck) string {
+	if check.DaysLeft == nil {
+		return check.Error
+	}
+
+	days := *check.DaysLeft
+	switch {
+	case days < 0:
+		return fmt.Sprintf("expired %s ago (%s)", pluralDays(-days), check.ExpirationDate)
+	case days == 0:
+		return fmt.Sprintf("expires today (%s)", check.ExpirationDate)
+	}
+	return fmt.Sprintf("expires in %s (%s)", pluralDays(days), check.ExpirationDate)
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// printExpiryText prints a Nagios-style status line followed by one line
+// per domain
+func printExpiryText(w io.Writer, report *expiryReport) error {
+	counts := expiryCounts(report.Domains)
+
+	var parts []string
+	for _, state := range []string{stateCritical, stateWarning, stateUnknown, stateOK} {
+		if counts[state] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[state], strings.ToLower(state)))
+		}
+	}
+	fmt.Fprintf(w, "EXPIRY %s - %s\n", report.Status, strings.Join(parts, ", "))
+
+	for _, check := range report.Domains {
+		fmt.Fprintf(w, "%-8s %s: %s\n", check.Status, check.Domain, expirySummary(check))
+	}
+	return nil
+}
+
+// printExpiryTable prints a compact table, one row per domain
+func printExpiryTable(w io.Writer, report *expiryReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tDOMAIN\tDAYS\tEXPIRES\tMETHOD")
+
+	for _, check := range report.Domains {
+		days := "-"
+		if check.DaysLeft != nil {
+			days = fmt.Sprintf("%d", *check.DaysLeft)
+		}
+		expires := check.ExpirationDate
+		if expires == "" {
+			expires = check.Error
+		}
+		method := check.Method
+		if method == "" {
+			method = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", check.Status, check.Domain, days, expires, method)
+	}
+	return tw.Flush()
+}
+
+func printExpiryJSON(w io.Writer, report *expiryReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var ipCmd = &cobra.Command{
+	Use:   "ip <address|cidr>",
+	Short: "Look up the network an IP address or prefix belongs to",
+	Long: `Performs an RDAP lookup for an IPv4 or IPv6 address or CIDR prefix at the
+regional internet registry responsible for it, found with the IANA ipv4 and
+ipv6 bootstrap files (cached locally like the domain bootstrap).
+
+Shows the network's name, handle, address range and CIDRs, country and
+contacts, including abuse contacts.
+
+Examples:
+  domaindetails ip 8.8.8.8
+  domaindetails ip 2001:4860:4860::8888 --json
+  domaindetails ip 192.0.2.0/24`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIP,
+}
+
+func init() {
+	rootCmd.AddCommand(ipCmd)
+}
+
+func runIP(cmd *cobra.Command, args []string) error {
+	if verbose {
+		fmt.Printf("RDAP lookup for IP: %s\n", args[0])
+	}
+
+	result, err := newRDAPClient(verbose).LookupIP(args[0])
+	if err != nil {
+		return fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+
+	printer := newPrinter()
+	return printer.PrintNetwork(result)
+}
@@ -2,11 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/simplebytes-com/domaindetails-cli/internal/output"
 	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
-	"github.com/simplebytes-com/domaindetails-cli/internal/whois"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +19,8 @@ providing structured JSON responses. Most major TLDs now support RDAP.
 Examples:
   domaindetails lookup example.com
   domaindetails lookup google.co.uk --json
-  domaindetails lookup github.io --raw`,
+  domaindetails lookup github.io --raw
+  domaindetails lookup www.example.co.uk --strip-subdomains`,
 	Args: cobra.ExactArgs(1),
 	RunE: runLookup,
 }
@@ -31,18 +30,38 @@ func init() {
 }
 
 func runLookup(cmd *cobra.Command, args []string) error {
-	domain := strings.ToLower(strings.TrimSpace(args[0]))
-
-	if !isValidDomain(domain) {
-		return fmt.Errorf("invalid domain format: %s", domain)
+	query, err := normalizeDomain(args[0])
+	if err != nil {
+		return err
 	}
+	domain := query.Domain
 
 	if verbose {
 		fmt.Printf("Looking up domain: %s\n", domain)
 	}
 
+	whoisClient, err := newWhoisClient(verbose)
+	if err != nil {
+		return err
+	}
+
+	result, err := cachedLookup("lookup", domain, func() (*types.LookupResult, error) {
+		return lookupDomain(newRDAPClient(verbose), whoisClient, domain, verbose)
+	})
+	if err != nil {
+		return err
+	}
+	query.annotate(result)
+
+	// Output results
+	printer := newPrinter()
+	return printer.Print(result)
+}
+
+// lookupDomain runs the RDAP-then-WHOIS flow for a single domain using the
+// given clients, so callers looking up many domains can share them
+func lookupDomain(rdapClient *rdap.Client, whoisClient whoisClient, domain string, verbose bool) (*types.LookupResult, error) {
 	// Try RDAP first
-	rdapClient := rdap.NewClient(verbose)
 	result, err := rdapClient.Lookup(domain)
 
 	if err != nil {
@@ -51,41 +70,13 @@ func runLookup(cmd *cobra.Command, args []string) error {
 		}
 
 		// Fall back to WHOIS
-		whoisClient := whois.NewClient(verbose)
+		rdapErr := err
 		result, err = whoisClient.Lookup(domain)
 
 		if err != nil {
-			return fmt.Errorf("lookup failed: %v", err)
-		}
-	}
-
-	// Output results
-	printer := output.NewPrinter(jsonOutput, rawOutput)
-	return printer.Print(result)
-}
-
-func isValidDomain(domain string) bool {
-	// Basic domain validation
-	if len(domain) < 3 || len(domain) > 253 {
-		return false
-	}
-
-	parts := strings.Split(domain, ".")
-	if len(parts) < 2 {
-		return false
-	}
-
-	for _, part := range parts {
-		if len(part) == 0 || len(part) > 63 {
-			return false
-		}
-		// Check for valid characters
-		for i, c := range part {
-			if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || (c == '-' && i > 0 && i < len(part)-1)) {
-				return false
-			}
+			return nil, &LookupError{RDAP: rdapErr, WHOIS: err}
 		}
 	}
 
-	return true
+	return result, nil
 }
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+	"github.com/simplebytes-com/domaindetails-cli/internal/whois"
+)
+
+// failingWhois is a WHOIS client whose lookups fail with err
+type failingWhois struct {
+	err error
+}
+
+func (w failingWhois) Lookup(domain string) (*types.LookupResult, error) {
+	return nil, w.err
+}
+
+// newLocalRDAPClient returns an RDAP client for the .test TLD served by a
+// local server answering every query with status, using a read-only cache
+// in a temporary directory
+func newLocalRDAPClient(t *testing.T, status int) *rdap.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	bootstrap := filepath.Join(dir, "dns.json")
+	data := fmt.Sprintf(`{"version":"1.0","services":[[["test"],["%s/"]]]}`, server.URL)
+	if err := os.WriteFile(bootstrap, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetDir(filepath.Join(dir, "cache"))
+	cache.SetBootstrapURL(bootstrap)
+	cache.SetReadOnly(true)
+	t.Cleanup(func() {
+		cache.SetDir("")
+		cache.SetBootstrapURL("")
+		cache.SetReadOnly(false)
+	})
+
+	client := rdap.NewClient(false)
+	client.SetMaxRetries(0)
+	return client
+}
+
+func TestLookupDomainFallbackExitCodes(t *testing.T) {
+	whoisDown := &whois.RequestError{Server: "whois.example", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name       string
+		rdapStatus int
+		whoisErr   error
+		exitCode   int
+	}{
+		// The RDAP failure decides, not the WHOIS network error
+		{"rdap rate limited", http.StatusTooManyRequests, whoisDown, ExitRateLimited},
+		{"rdap server error", http.StatusBadGateway, whoisDown, ExitServerError},
+		// Without an RDAP server, the WHOIS failure decides
+		{"no rdap server", 0, whoisDown, ExitNetwork},
+		{"no server at all", 0, whois.ErrNoWhoisServer, ExitUnsupported},
+		// Without a WHOIS server, the RDAP failure decides
+		{"no whois server", http.StatusTooManyRequests, whois.ErrNoWhoisServer, ExitRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newLocalRDAPClient(t, tt.rdapStatus)
+			domain := "example.test"
+			if tt.rdapStatus == 0 {
+				domain = "example.unserved"
+			}
+
+			_, err := lookupDomain(client, failingWhois{tt.whoisErr}, domain, false)
+			var lookupErr *LookupError
+			if !errors.As(err, &lookupErr) {
+				t.Fatalf("err = %v, want a LookupError", err)
+			}
+			if got := classifyError(err).ExitCode; got != tt.exitCode {
+				t.Errorf("exit code = %d, want %d (err: %v)", got, tt.exitCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/spf13/cobra"
+)
+
+var nameserverCmd = &cobra.Command{
+	Use:   "nameserver <host>",
+	Short: "Look up a nameserver at its registry using RDAP",
+	Long: `Performs an RDAP lookup for a nameserver object at the registry of its TLD,
+showing the IP addresses registered for it (glue records) and its status.
+
+Examples:
+  domaindetails nameserver ns1.google.com
+  domaindetails nameserver a.iana-servers.net --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNameserver,
+}
+
+func init() {
+	rootCmd.AddCommand(nameserverCmd)
+}
+
+func runNameserver(cmd *cobra.Command, args []string) error {
+	input := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(args[0])), ".")
+
+	host, err := idn.ToASCII(input)
+	if err != nil || !isValidDomain(host) {
+		return inputError("invalid nameserver name: %s", input)
+	}
+
+	if verbose {
+		fmt.Printf("RDAP lookup for nameserver: %s\n", host)
+	}
+
+	result, err := newRDAPClient(verbose).LookupNameserver(host)
+	if err != nil {
+		return fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+
+	printer := newPrinter()
+	return printer.PrintNameserver(result)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/simplebytes-com/domaindetails-cli/internal/whois"
+	"github.com/simplebytes-com/domaindetails-cli/internal/whoisparser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	parseWhoisServer string
+	parseWhoisDomain string
+)
+
+var parseWhoisCmd = &cobra.Command{
+	Use:   "parse-whois <file>",
+	Short: "Parse a saved raw WHOIS response",
+	Long: `Parses a raw WHOIS response from a file (or stdin when the file is "-")
+using the built-in WHOIS parser, without any network access.
+
+The input may be a single server's response or the raw output of a native
+lookup (--whois-source native --raw), which records each server's response
+in the referral chain. Use --server to select the server-specific parsing
+rules for a single response when the domain's TLD isn't enough.
+
+Examples:
+  domaindetails parse-whois saved.txt
+  domaindetails parse-whois saved.txt --server whois.nic.uk --json
+  domaindetails whois example.com --whois-source native --raw | domaindetails parse-whois -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runParseWhois,
+}
+
+func init() {
+	rootCmd.AddCommand(parseWhoisCmd)
+	parseWhoisCmd.Flags().StringVar(&parseWhoisServer, "server", "", "WHOIS server the response came from")
+	parseWhoisCmd.Flags().StringVar(&parseWhoisDomain, "domain", "", "Domain the response is for (detected when omitted)")
+}
+
+func runParseWhois(cmd *cobra.Command, args []string) error {
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read input: %v", err)
+	}
+
+	responses := whois.SplitResponses(string(data))
+	if len(responses) == 0 {
+		return fmt.Errorf("no WHOIS response found in input")
+	}
+
+	if parseWhoisServer != "" {
+		responses[0].Server = parseWhoisServer
+	}
+
+	domain := strings.ToLower(strings.TrimSpace(parseWhoisDomain))
+	if domain == "" {
+		domain = whoisparser.Parse("", responses[0].Server, responses[0].Body).DomainName
+	}
+
+	result := whois.ParseResponses(domain, responses)
+	result.UnicodeDomain = idn.UnicodeName(result.Domain)
+
+	printer := newPrinter()
+	return printer.Print(result)
+}
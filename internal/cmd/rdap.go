@@ -2,10 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/simplebytes-com/domaindetails-cli/internal/output"
-	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
 	"github.com/spf13/cobra"
 )
 
@@ -30,23 +28,25 @@ func init() {
 }
 
 func runRdap(cmd *cobra.Command, args []string) error {
-	domain := strings.ToLower(strings.TrimSpace(args[0]))
-
-	if !isValidDomain(domain) {
-		return fmt.Errorf("invalid domain format: %s", domain)
+	query, err := normalizeDomain(args[0])
+	if err != nil {
+		return err
 	}
+	domain := query.Domain
 
 	if verbose {
 		fmt.Printf("RDAP lookup for domain: %s\n", domain)
 	}
 
-	rdapClient := rdap.NewClient(verbose)
-	result, err := rdapClient.Lookup(domain)
+	result, err := cachedLookup("rdap", domain, func() (*types.LookupResult, error) {
+		return newRDAPClient(verbose).Lookup(domain)
+	})
 
 	if err != nil {
-		return fmt.Errorf("RDAP lookup failed: %v", err)
+		return fmt.Errorf("RDAP lookup failed: %w", err)
 	}
+	query.annotate(result)
 
-	printer := output.NewPrinter(jsonOutput, rawOutput)
+	printer := newPrinter()
 	return printer.Print(result)
 }
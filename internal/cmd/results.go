@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// ResultTTLEnv is the environment variable that sets the default results
+// cache TTL
+const ResultTTLEnv = "DOMAINDETAILS_CACHE_TTL"
+
+var (
+	resultsOnce  sync.Once
+	resultsCache *cache.Cache
+)
+
+// defaultResultTTL returns the results cache TTL from the environment, or
+// zero (disabled) when unset or invalid
+func defaultResultTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv(ResultTTLEnv))
+	if err != nil {
+		return 0
+	}
+	return ttl
+}
+
+// cacheMethod returns the results cache key for a lookup method. WHOIS
+// results from the native client are kept apart from the API's.
+func cacheMethod(method string) string {
+	if method != "rdap" && whoisSource == "native" {
+		return method + "-native"
+	}
+	return method
+}
+
+// cachedLookup runs a lookup through the results cache. The cache is only
+// used when a TTL is set (--cache-ttl or DOMAINDETAILS_CACHE_TTL) and not
+// disabled with --no-cache; --refresh skips reading but still stores.
+// Failed lookups are never cached.
+func cachedLookup(method, domain string, lookup func() (*types.LookupResult, error)) (*types.LookupResult, error) {
+	if resultTTL <= 0 || noCache {
+		return lookup()
+	}
+
+	resultsOnce.Do(func() {
+		resultsCache = cache.NewCache()
+	})
+
+	method = cacheMethod(method)
+
+	if !refreshCache {
+		if entry, ok := resultsCache.GetResult(domain, method); ok {
+			result := entry.Result
+			result.CachedAt = entry.StoredAt.UTC().Format(time.RFC3339)
+			return result, nil
+		}
+	}
+
+	result, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resultsCache.PutResult(domain, method, result, resultTTL); err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Failed to cache result for %s: %v\n", domain, err)
+	}
+
+	return result, nil
+}
@@ -2,8 +2,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/output"
+	"github.com/simplebytes-com/domaindetails-cli/internal/paths"
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+	"github.com/simplebytes-com/domaindetails-cli/internal/whois"
 	"github.com/spf13/cobra"
 )
 
@@ -14,11 +24,52 @@ var (
 	dateStr    string
 
 	// Global flags
-	jsonOutput bool
-	rawOutput  bool
-	verbose    bool
+	jsonOutput    bool
+	rawOutput     bool
+	verbose       bool
+	showNotices   bool
+	explainStatus bool
+
+	// RDAP politeness flags
+	rdapRateLimit  float64
+	rdapBurst      int
+	rdapMaxRetries int
+	noReferral     bool
+
+	// Strip subdomains down to the registrable domain before lookups
+	stripSubdomains bool
+
+	// WHOIS source: "api" (DomainDetails.com API) or "native" (port 43)
+	whoisSource string
+
+	// Results cache flags
+	resultTTL    time.Duration
+	noCache      bool
+	refreshCache bool
+
+	// Where the RDAP bootstrap file is fetched from
+	bootstrapURL string
+
+	// Cache location and read-only mode
+	cacheDir      string
+	cacheReadOnly bool
+
+	// Configuration profile and the HTTP settings profiles can set
+	profileName string
+	timeout     time.Duration
+	apiBaseURL  string
+	proxyURL    string
+	userAgent   string
 )
 
+// BootstrapURLEnv is the environment variable that sets the RDAP bootstrap
+// URL, such as a mirror of data.iana.org/rdap/dns.json
+const BootstrapURLEnv = "DOMAINDETAILS_BOOTSTRAP_URL"
+
+// CacheReadOnlyEnv is the environment variable that makes the cache
+// read-only when set to true
+const CacheReadOnlyEnv = "DOMAINDETAILS_CACHE_READONLY"
+
 // SetVersionInfo sets version information from build
 func SetVersionInfo(version, commit, date string) {
 	versionStr = version
@@ -47,12 +98,82 @@ Examples:
 
 Documentation: https://domaindetails.com/kb/cli
 Source: https://github.com/simplebytes-com/domaindetails-cli`,
-	Version: versionStr,
+	Version:       versionStr,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		commandStarted = true
+		if err := applyProfile(cmd); err != nil {
+			return err
+		}
+		if err := setProxy(proxyURL); err != nil {
+			return err
+		}
+		cache.SetBootstrapURL(bootstrapURL)
+		cache.SetDir(cacheDir)
+		cache.SetReadOnly(cacheReadOnly)
+		return nil
+	},
+}
+
+// ExitError makes the process exit with a specific code. Err, if set, is
+// printed before exiting.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
 }
 
-// Execute runs the root command
+// commandStarted is set once the arguments and flags have been parsed and
+// validated, so errors returned before it are usage errors
+var commandStarted bool
+
+// Execute runs the root command. Failures are printed here, as JSON with
+// --json, and returned as an ExitError carrying the exit code.
 func Execute() error {
-	return rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return nil
+	}
+
+	// Commands that exit with their own codes keep them; with --json their
+	// error is still reported as JSON
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.Err == nil || !jsonOutput {
+			return err
+		}
+		result := classifyError(exitErr.Err)
+		result.ExitCode = exitErr.Code
+		newPrinter().PrintError(result)
+		return &ExitError{Code: exitErr.Code}
+	}
+
+	if !commandStarted {
+		err = &InputError{Err: err}
+	}
+
+	result := classifyError(err)
+	// Any other expiry failure, such as an invalid flag, is Nagios UNKNOWN
+	if cmd == expiryCmd {
+		result.ExitCode = ExitUnknown
+	}
+	newPrinter().PrintError(result)
+	if result.Code == codeInvalidInput && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
+	}
+
+	return &ExitError{Code: result.ExitCode}
 }
 
 func init() {
@@ -60,4 +181,78 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
 	rootCmd.PersistentFlags().BoolVarP(&rawOutput, "raw", "r", false, "Include raw response data")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().BoolVar(&showNotices, "notices", false, "Show RDAP notices, remarks, conformance and links in text output")
+	rootCmd.PersistentFlags().BoolVar(&explainStatus, "explain-status", false, "Explain each EPP status and summarize the domain's locks in text output")
+	rootCmd.PersistentFlags().Float64Var(&rdapRateLimit, "rate-limit", rdap.DefaultRateLimit, "Max RDAP requests per second per server (0 disables)")
+	rootCmd.PersistentFlags().IntVar(&rdapBurst, "burst", int(rdap.DefaultRateLimit), "Max burst of RDAP requests per server")
+	rootCmd.PersistentFlags().IntVar(&rdapMaxRetries, "retries", rdap.DefaultMaxRetries, "Retries for throttled (429) or unavailable RDAP servers")
+	rootCmd.PersistentFlags().BoolVar(&noReferral, "no-referral", false, "Don't follow registrar RDAP referrals from thin registries")
+	rootCmd.PersistentFlags().BoolVar(&stripSubdomains, "strip-subdomains", false, "Look up the registrable domain (www.example.co.uk -> example.co.uk)")
+	rootCmd.PersistentFlags().StringVar(&whoisSource, "whois-source", "api", "WHOIS source: api (DomainDetails.com API) or native (port 43)")
+	rootCmd.PersistentFlags().DurationVar(&resultTTL, "cache-ttl", defaultResultTTL(), "Cache lookup results for this long, e.g. 1h (0 disables; env "+ResultTTLEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Don't read or write cached lookup results")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "Ignore cached lookup results but store fresh ones")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Cache directory (env "+paths.CacheDirEnv+"; default under $XDG_CACHE_HOME)")
+	rootCmd.PersistentFlags().BoolVar(&cacheReadOnly, "cache-read-only", defaultCacheReadOnly(), "Use the cache without writing to it (env "+CacheReadOnlyEnv+")")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", os.Getenv(ProfileEnv), "Configuration profile to use (env "+ProfileEnv+")")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Timeout for each RDAP and WHOIS request, e.g. 30s (0 uses the defaults)")
+	rootCmd.PersistentFlags().StringVar(&apiBaseURL, "api-url", whois.APIBaseURL, "DomainDetails.com API base URL for WHOIS lookups")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "HTTP proxy URL for RDAP, API and bootstrap requests")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", rdap.UserAgent, "User-Agent sent with RDAP and API requests")
+	rootCmd.PersistentFlags().StringVar(&bootstrapURL, "bootstrap-url", os.Getenv(BootstrapURLEnv), "RDAP bootstrap file URL or path, e.g. a mirror of IANA's dns.json (env "+BootstrapURLEnv+")")
+}
+
+// defaultCacheReadOnly returns whether the environment makes the cache
+// read-only
+func defaultCacheReadOnly() bool {
+	ro, _ := strconv.ParseBool(os.Getenv(CacheReadOnlyEnv))
+	return ro
+}
+
+// newPrinter creates a printer configured from the global flags
+func newPrinter() *output.Printer {
+	printer := output.NewPrinter(jsonOutput, rawOutput)
+	printer.SetShowNotices(showNotices)
+	printer.SetExplainStatus(explainStatus)
+	return printer
+}
+
+// newRDAPClient creates an RDAP client configured from the global flags
+func newRDAPClient(verbose bool) *rdap.Client {
+	client := rdap.NewClient(verbose)
+	client.SetRateLimit(rdapRateLimit, rdapBurst)
+	client.SetMaxRetries(rdapMaxRetries)
+	client.SetFollowReferrals(!noReferral)
+	client.SetUserAgent(userAgent)
+	if timeout > 0 {
+		client.SetTimeout(timeout)
+	}
+	return client
+}
+
+// whoisClient is implemented by both the API and native WHOIS clients
+type whoisClient interface {
+	Lookup(domain string) (*types.LookupResult, error)
+}
+
+// newWhoisClient creates the WHOIS client selected by --whois-source
+func newWhoisClient(verbose bool) (whoisClient, error) {
+	switch whoisSource {
+	case "api", "":
+		client := whois.NewClient(verbose)
+		client.SetBaseURL(apiBaseURL)
+		client.SetUserAgent(userAgent)
+		if timeout > 0 {
+			client.SetTimeout(timeout)
+		}
+		return client, nil
+	case "native":
+		client := whois.NewNativeClient(verbose)
+		if timeout > 0 {
+			client.SetTimeout(timeout)
+		}
+		return client, nil
+	default:
+		return nil, inputError("invalid WHOIS source %q (expected api or native)", whoisSource)
+	}
 }
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/simplebytes-com/domaindetails-cli/internal/rdap"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchName         string
+	searchNameserver   string
+	searchNameserverIP string
+	searchIP           string
+	searchTLD          string
+	searchServer       string
+	searchMaxPages     int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <domains|nameservers>",
+	Short: "Search a registry for domains or nameservers using RDAP",
+	Long: `Runs an RDAP search (RFC 9082) on a registry's RDAP server. Searches are
+optional for RDAP servers and many registries don't offer them (or only to
+authenticated users); unsupported searches are reported as such.
+
+Domains can be searched by name (with a "*" wildcard), by nameserver name
+(--nameserver) or by nameserver address (--nameserver-ip). Nameservers can
+be searched by name or by address (--ip).
+
+The server is the RDAP server of --tld, or of the TLD of the searched name,
+unless given with --server. Paged results are followed up to --max-pages;
+truncated result sets are flagged.
+
+Examples:
+  domaindetails search domains --name 'exam*.example'
+  domaindetails search domains --nameserver ns1.compromised.example --tld example
+  domaindetails search nameservers --ip 192.0.2.1 --tld example
+  domaindetails search domains --name 'exam*.example' --server https://rdap.example/ --json`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"domains", "nameservers"},
+	RunE:      runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().StringVar(&searchName, "name", "", "Search by name (\"*\" matches any characters)")
+	searchCmd.Flags().StringVar(&searchNameserver, "nameserver", "", "Search domains by nameserver name")
+	searchCmd.Flags().StringVar(&searchNameserverIP, "nameserver-ip", "", "Search domains by nameserver IP address")
+	searchCmd.Flags().StringVar(&searchIP, "ip", "", "Search nameservers by IP address")
+	searchCmd.Flags().StringVar(&searchTLD, "tld", "", "Search the registry of this TLD")
+	searchCmd.Flags().StringVar(&searchServer, "server", "", "RDAP server base URL to search")
+	searchCmd.Flags().IntVar(&searchMaxPages, "max-pages", rdap.DefaultSearchMaxPages, "Maximum number of result pages to fetch")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := rdap.SearchQuery{Object: args[0]}
+
+	var set []string
+	for field, value := range map[string]string{
+		"name":      searchName,
+		"nsLdhName": searchNameserver,
+		"nsIp":      searchNameserverIP,
+		"ip":        searchIP,
+	} {
+		if value != "" {
+			query.Field, query.Value = field, strings.TrimSpace(value)
+			set = append(set, field)
+		}
+	}
+	if len(set) != 1 {
+		return inputError("exactly one of --name, --nameserver, --nameserver-ip or --ip is required")
+	}
+
+	// Names without wildcards are searched by their A-labels
+	if (query.Field == "name" || query.Field == "nsLdhName") && !strings.Contains(query.Value, "*") {
+		ascii, err := idn.ToASCII(strings.ToLower(query.Value))
+		if err != nil {
+			return inputError("invalid name: %s: %v", query.Value, err)
+		}
+		query.Value = ascii
+	}
+
+	if err := query.Validate(); err != nil {
+		return err
+	}
+
+	client := newRDAPClient(verbose)
+
+	serverURL := searchServer
+	if serverURL == "" {
+		name := strings.TrimPrefix(searchTLD, ".")
+		if name == "" {
+			name = searchTLDOf(query)
+		}
+		if name == "" {
+			return inputError("--tld or --server is required for %s searches", query.Field)
+		}
+
+		var err error
+		serverURL, err = client.ServerFor(strings.ToLower(name))
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := client.Search(serverURL, query, searchMaxPages)
+	if err != nil {
+		return fmt.Errorf("RDAP search failed: %w", err)
+	}
+
+	printer := newPrinter()
+	return printer.PrintSearch(result)
+}
+
+// searchTLDOf returns the TLD of the searched name, or "" for address
+// searches and names whose TLD is a wildcard
+func searchTLDOf(query rdap.SearchQuery) string {
+	if query.Field != "name" && query.Field != "nsLdhName" {
+		return ""
+	}
+
+	labels := strings.Split(strings.TrimSuffix(query.Value, "."), ".")
+	tld := labels[len(labels)-1]
+	if len(labels) < 2 || strings.Contains(tld, "*") {
+		return ""
+	}
+	return tld
+}
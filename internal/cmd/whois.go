@@ -2,26 +2,29 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/simplebytes-com/domaindetails-cli/internal/output"
-	"github.com/simplebytes-com/domaindetails-cli/internal/whois"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var whoisCmd = &cobra.Command{
 	Use:   "whois <domain>",
 	Short: "Look up domain using WHOIS only",
-	Long: `Performs a domain lookup using WHOIS only via the DomainDetails.com API.
+	Long: `Performs a domain lookup using WHOIS only.
 
-WHOIS is the traditional domain registration lookup protocol. This command
-queries the DomainDetails.com API which handles the raw WHOIS queries and
-parses the responses using the open-source @domaindetails/whois-parser.
+WHOIS is the traditional domain registration lookup protocol. By default this
+command queries the DomainDetails.com API which handles the raw WHOIS queries
+and parses the responses using the open-source @domaindetails/whois-parser.
+
+With --whois-source native the CLI queries WHOIS servers directly over TCP
+port 43, starting at whois.iana.org and following referrals to the registry
+and registrar servers. No data is sent to DomainDetails.com.
 
 Examples:
   domaindetails whois example.com
   domaindetails whois google.co.uk --json
-  domaindetails whois github.io --raw`,
+  domaindetails whois github.io --raw
+  domaindetails whois example.com --whois-source native`,
 	Args: cobra.ExactArgs(1),
 	RunE: runWhois,
 }
@@ -31,23 +34,30 @@ func init() {
 }
 
 func runWhois(cmd *cobra.Command, args []string) error {
-	domain := strings.ToLower(strings.TrimSpace(args[0]))
-
-	if !isValidDomain(domain) {
-		return fmt.Errorf("invalid domain format: %s", domain)
+	query, err := normalizeDomain(args[0])
+	if err != nil {
+		return err
 	}
+	domain := query.Domain
 
 	if verbose {
 		fmt.Printf("WHOIS lookup for domain: %s\n", domain)
 	}
 
-	whoisClient := whois.NewClient(verbose)
-	result, err := whoisClient.Lookup(domain)
+	whoisClient, err := newWhoisClient(verbose)
+	if err != nil {
+		return err
+	}
+
+	result, err := cachedLookup("whois", domain, func() (*types.LookupResult, error) {
+		return whoisClient.Lookup(domain)
+	})
 
 	if err != nil {
-		return fmt.Errorf("WHOIS lookup failed: %v", err)
+		return fmt.Errorf("WHOIS lookup failed: %w", err)
 	}
+	query.annotate(result)
 
-	printer := output.NewPrinter(jsonOutput, rawOutput)
+	printer := newPrinter()
 	return printer.Print(result)
 }
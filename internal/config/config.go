@@ -0,0 +1,286 @@
+// Package config reads and writes the CLI's configuration file, which holds
+// named profiles of default settings
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file in the config directory
+const FileName = "config.yaml"
+
+// DefaultProfileName is the profile used when none is selected
+const DefaultProfileName = "default"
+
+// Keys are the settings a profile can hold, in display order
+var Keys = []string{
+	"output",
+	"timeout",
+	"whois_source",
+	"api_base_url",
+	"proxy",
+	"concurrency",
+	"user_agent",
+	"cache_ttl",
+	"bootstrap_url",
+	"cache_dir",
+}
+
+// Profile is a named set of default settings. Empty fields are unset.
+type Profile struct {
+	Output      string `yaml:"output,omitempty"`
+	Timeout     string `yaml:"timeout,omitempty"`
+	WhoisSource string `yaml:"whois_source,omitempty"`
+	APIBaseURL  string `yaml:"api_base_url,omitempty"`
+	Proxy       string `yaml:"proxy,omitempty"`
+	Concurrency int    `yaml:"concurrency,omitempty"`
+	UserAgent   string `yaml:"user_agent,omitempty"`
+	CacheTTL    string `yaml:"cache_ttl,omitempty"`
+
+	BootstrapURL string `yaml:"bootstrap_url,omitempty"`
+	CacheDir     string `yaml:"cache_dir,omitempty"`
+}
+
+// Config is the contents of the configuration file
+type Config struct {
+	DefaultProfile string              `yaml:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// Path returns the path of the configuration file
+func Path() (string, error) {
+	dir := paths.ConfigDir()
+	if dir == "" {
+		return "", errors.New("no configuration directory (set " + paths.ConfigDirEnv + ")")
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads a configuration file. A missing file is an empty
+// configuration.
+func Load(path string) (*Config, error) {
+	config := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	for name, profile := range config.Profiles {
+		if profile == nil {
+			config.Profiles[name] = &Profile{}
+			continue
+		}
+		for _, key := range Keys {
+			value, _ := profile.Get(key)
+			if err := validate(key, value); err != nil {
+				return nil, fmt.Errorf("invalid config %s: profile %s: %v", path, name, err)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// Save writes the configuration file, creating its directory if needed
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return nil
+}
+
+// ProfileName returns the profile to use: the one asked for, else the
+// configured default, else "default"
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if c.DefaultProfile != "" {
+		return c.DefaultProfile
+	}
+	return DefaultProfileName
+}
+
+// Profile returns a profile by name
+func (c *Config) Profile(name string) (*Profile, bool) {
+	profile, ok := c.Profiles[name]
+	return profile, ok
+}
+
+// ProfileNames returns the profile names in order
+func (c *Config) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set sets a key in a profile, creating the profile if needed. An empty
+// value unsets the key.
+func (c *Config) Set(profileName, key, value string) error {
+	profile, ok := c.Profiles[profileName]
+	if !ok {
+		profile = &Profile{}
+	}
+	if err := profile.Set(key, value); err != nil {
+		return err
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	c.Profiles[profileName] = profile
+	return nil
+}
+
+// Get returns the value of a key, or an empty string if it's unset
+func (p *Profile) Get(key string) (string, error) {
+	switch key {
+	case "output":
+		return p.Output, nil
+	case "timeout":
+		return p.Timeout, nil
+	case "whois_source":
+		return p.WhoisSource, nil
+	case "api_base_url":
+		return p.APIBaseURL, nil
+	case "proxy":
+		return p.Proxy, nil
+	case "concurrency":
+		if p.Concurrency == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(p.Concurrency), nil
+	case "user_agent":
+		return p.UserAgent, nil
+	case "cache_ttl":
+		return p.CacheTTL, nil
+	case "bootstrap_url":
+		return p.BootstrapURL, nil
+	case "cache_dir":
+		return p.CacheDir, nil
+	}
+	return "", unknownKey(key)
+}
+
+// Set sets the value of a key after validating it. An empty value unsets
+// the key.
+func (p *Profile) Set(key, value string) error {
+	if err := validate(key, value); err != nil {
+		return err
+	}
+
+	switch key {
+	case "output":
+		p.Output = value
+	case "timeout":
+		p.Timeout = value
+	case "whois_source":
+		p.WhoisSource = value
+	case "api_base_url":
+		p.APIBaseURL = strings.TrimRight(value, "/")
+	case "proxy":
+		p.Proxy = value
+	case "concurrency":
+		p.Concurrency, _ = strconv.Atoi(value)
+	case "user_agent":
+		p.UserAgent = value
+	case "cache_ttl":
+		p.CacheTTL = value
+	case "bootstrap_url":
+		p.BootstrapURL = value
+	case "cache_dir":
+		p.CacheDir = value
+	}
+	return nil
+}
+
+// validate checks a value is valid for a key. Empty values are always
+// valid.
+func validate(key, value string) error {
+	if value == "" {
+		for _, k := range Keys {
+			if k == key {
+				return nil
+			}
+		}
+		return unknownKey(key)
+	}
+
+	switch key {
+	case "output":
+		if value != "text" && value != "json" && value != "table" {
+			return fmt.Errorf("invalid output %q (expected text, json or table)", value)
+		}
+	case "timeout", "cache_ttl":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid %s %q (expected a duration such as 30s)", key, value)
+		}
+	case "whois_source":
+		if value != "api" && value != "native" {
+			return fmt.Errorf("invalid whois_source %q (expected api or native)", value)
+		}
+	case "api_base_url", "proxy":
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid %s %q (expected a URL)", key, value)
+		}
+	case "bootstrap_url":
+		// Like --bootstrap-url, a local file can stand in for a mirror
+		if filepath.IsAbs(value) {
+			break
+		}
+		u, err := url.Parse(value)
+		if err != nil || !(u.Scheme == "file" && u.Path != "" || (u.Scheme == "http" || u.Scheme == "https") && u.Host != "") {
+			return fmt.Errorf("invalid bootstrap_url %q (expected an http(s) URL or an absolute file path)", value)
+		}
+	case "cache_dir":
+		if !filepath.IsAbs(value) {
+			return fmt.Errorf("invalid cache_dir %q (expected an absolute path)", value)
+		}
+	case "concurrency":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid concurrency %q (expected a number of at least 1)", value)
+		}
+	case "user_agent":
+	default:
+		return unknownKey(key)
+	}
+	return nil
+}
+
+// unknownKey returns the error for a key profiles don't have
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown key %q (expected one of %s)", key, strings.Join(Keys, ", "))
+}
@@ -0,0 +1,196 @@
+// Package dates parses the date formats used by RDAP and WHOIS servers and
+// describes dates relative to now
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// layouts are the date formats seen in registry responses, tried in order.
+// Dates without a zone are taken to be UTC.
+var layouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05-07",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006.01.02 15:04:05",
+	"2006.01.02",
+	"2006. 01. 02.",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"02-Jan-2006 15:04:05",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"02-January-2006",
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+	"2 January 2006",
+	"January 2 2006",
+	"Jan 2 2006",
+	time.UnixDate,
+	time.ANSIC,
+	"20060102",
+}
+
+var (
+	// parenthesized notes such as "(JST)" or "(YYYY-MM-DD)"
+	notePattern     = regexp.MustCompile(`\s*\([^)]*\)`)
+	zoneNotePattern = regexp.MustCompile(`^\s*\(([A-Z]{2,5})\)$`)
+	spacePattern    = regexp.MustCompile(`\s+`)
+)
+
+// zoneOffsets are the UTC offsets, in hours, of the zone abbreviations
+// registries use. Ambiguous ones such as CST and IST are left out.
+var zoneOffsets = map[string]int{
+	"UTC":  0,
+	"GMT":  0,
+	"WET":  0,
+	"CET":  1,
+	"CEST": 2,
+	"EET":  2,
+	"EEST": 3,
+	"MSK":  3,
+	"HKT":  8,
+	"SGT":  8,
+	"AWST": 8,
+	"JST":  9,
+	"KST":  9,
+	"AEST": 10,
+	"AEDT": 11,
+	"NZST": 12,
+	"NZDT": 13,
+	"BRT":  -3,
+	"EST":  -5,
+	"EDT":  -4,
+	"PST":  -8,
+	"PDT":  -7,
+}
+
+// Parse parses a registry date into UTC. A zone given as a note, as in
+// "2024/04/01 01:05:03 (JST)", applies to dates without one of their own;
+// dates in a zone it doesn't know are an error rather than taken as UTC.
+func Parse(value string) (time.Time, error) {
+	zone := ""
+	s := notePattern.ReplaceAllStringFunc(value, func(note string) string {
+		if m := zoneNotePattern.FindStringSubmatch(note); m != nil {
+			zone = m[1]
+		}
+		return ""
+	})
+	s = spacePattern.ReplaceAllString(strings.TrimSpace(s), " ")
+	s = strings.TrimSuffix(s, " UTC")
+	s = strings.TrimSuffix(s, " GMT")
+	s = strings.Replace(s, ",", "", -1)
+
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if !hasZone(layout) {
+			if zone != "" {
+				return inZone(t, zone)
+			}
+			return t.UTC(), nil
+		}
+		// time.Parse gives abbreviations it doesn't know a zero offset
+		if name, offset := t.Zone(); offset == 0 && strings.Contains(layout, "MST") {
+			return inZone(t, name)
+		}
+		return t.UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date: %s", value)
+}
+
+// hasZone reports whether a layout includes a zone
+func hasZone(layout string) bool {
+	return strings.Contains(layout, "Z07") || strings.Contains(layout, "-07") || strings.Contains(layout, "MST")
+}
+
+// inZone reads the wall clock time of t in the zone with the given
+// abbreviation
+func inZone(t time.Time, zone string) (time.Time, error) {
+	offset, ok := zoneOffsets[zone]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown time zone: %s", zone)
+	}
+	loc := time.FixedZone(zone, offset*60*60)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc).UTC(), nil
+}
+
+// Normalize parses a registry date, returning nil when it can't be parsed
+func Normalize(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	t, err := Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// Relative describes t relative to now, such as "in 42 days", "12 years
+// ago" or "in less than a day"
+func Relative(t, now time.Time) string {
+	if t.After(now) {
+		if d := span(now, t); d != "" {
+			return "in " + d
+		}
+		return "in less than a day"
+	}
+	if d := span(t, now); d != "" {
+		return d + " ago"
+	}
+	return "less than a day ago"
+}
+
+// span describes the time between from and to (from before to) in the
+// largest whole unit that fits: years, then months, then days. It is empty
+// when less than a day apart.
+func span(from, to time.Time) string {
+	years, months := 0, 0
+	for !from.AddDate(years+1, 0, 0).After(to) {
+		years++
+	}
+	if years > 0 {
+		return plural(years, "year")
+	}
+
+	for !from.AddDate(0, months+1, 0).After(to) {
+		months++
+	}
+	days := int(to.Sub(from).Hours() / 24)
+	switch {
+	case months >= 2:
+		return plural(months, "month")
+	case days >= 1:
+		return plural(days, "day")
+	}
+	return ""
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
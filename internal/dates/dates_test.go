@@ -0,0 +1,88 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z"},
+		{"2024-01-02T03:04:05+02:00", "2024-01-02T01:04:05Z"},
+		{"2024-01-02T03:04:05.123Z", "2024-01-02T03:04:05.123Z"},
+		{"2024-01-02 03:04:05", "2024-01-02T03:04:05Z"},
+		{"2024-01-02 03:04:05 UTC", "2024-01-02T03:04:05Z"},
+		{"2024-01-02", "2024-01-02T00:00:00Z"},
+		{"2024.01.02", "2024-01-02T00:00:00Z"},
+		{"2024. 01. 02.", "2024-01-02T00:00:00Z"},
+		{"02-Jan-2024", "2024-01-02T00:00:00Z"},
+		{"02.01.2024", "2024-01-02T00:00:00Z"},
+		{"January 2, 2024", "2024-01-02T00:00:00Z"},
+		{"20240102", "2024-01-02T00:00:00Z"},
+		{"2024-01-02 (YYYY-MM-DD)", "2024-01-02T00:00:00Z"},
+		// Zone notes and abbreviations apply their offset
+		{"2024/04/01 01:05:03 (JST)", "2024-03-31T16:05:03Z"},
+		{"2024/04/01 (JST)", "2024-03-31T15:00:00Z"},
+		{"2024-01-02 03:04:05 CET", "2024-01-02T02:04:05Z"},
+		{"2024-01-02 03:04:05 EST", "2024-01-02T08:04:05Z"},
+		// A zone in the date itself wins over a note
+		{"2024-01-02T03:04:05Z (JST)", "2024-01-02T03:04:05Z"},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.value)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.value, err)
+			continue
+		}
+		if s := got.Format(time.RFC3339Nano); s != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.value, s, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"(JST)",
+		"not a date",
+		"2024-13-45",
+		// Zones that aren't known must not be read as UTC
+		"2024/04/01 01:05:03 (XYZ)",
+		"2024-01-02 03:04:05 XYZ",
+	} {
+		if got, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", value, got)
+		}
+	}
+}
+
+func TestRelative(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now, "less than a day ago"},
+		{now.Add(5 * time.Hour), "in less than a day"},
+		{now.Add(-5 * time.Hour), "less than a day ago"},
+		{now.Add(24 * time.Hour), "in 1 day"},
+		{now.Add(-24 * time.Hour), "1 day ago"},
+		{now.Add(47 * time.Hour), "in 1 day"},
+		{now.AddDate(0, 0, 42), "in 42 days"},
+		{now.AddDate(0, 1, 0), "in 31 days"},
+		{now.AddDate(0, 2, 0), "in 2 months"},
+		{now.AddDate(0, -2, 0), "2 months ago"},
+		{now.AddDate(0, 11, 0), "in 11 months"},
+		{now.AddDate(1, 0, 0).Add(-time.Hour), "in 11 months"},
+		{now.AddDate(1, 0, 0), "in 1 year"},
+		{now.AddDate(-12, 0, 0), "12 years ago"},
+	}
+	for _, tt := range tests {
+		if got := Relative(tt.t, now); got != tt.want {
+			t.Errorf("Relative(%s) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package epp normalizes domain status values to EPP status codes and
+// explains what they mean
+package epp
+
+import (
+	"strings"
+)
+
+// Which party set a status
+const (
+	SideClient = "client"
+	SideServer = "server"
+)
+
+// Kinds of status
+const (
+	CategoryLock    = "lock"
+	CategoryHold    = "hold"
+	CategoryPending = "pending"
+	CategoryPeriod  = "period"
+	CategoryState   = "state"
+	CategoryOther   = "other"
+)
+
+// Info describes an EPP status code
+type Info struct {
+	Code        string
+	Side        string
+	Category    string
+	Description string
+}
+
+// statuses are the EPP domain status codes (RFC 5731, and RFC 3915 for the
+// grace periods) and the RDAP values that map to them (RFC 8056)
+var statuses = []Info{
+	{"ok", "", CategoryState, "No pending operations or restrictions"},
+	{"inactive", "", CategoryState, "No nameservers are delegated, so the domain doesn't resolve"},
+	{"clientDeleteProhibited", SideClient, CategoryLock, "The registrar won't let the domain be deleted"},
+	{"clientHold", SideClient, CategoryHold, "The registrar has removed the domain from DNS"},
+	{"clientRenewProhibited", SideClient, CategoryLock, "The registrar won't let the domain be renewed"},
+	{"clientTransferProhibited", SideClient, CategoryLock, "The registrar won't let the domain be transferred to another registrar"},
+	{"clientUpdateProhibited", SideClient, CategoryLock, "The registrar won't let the domain be changed"},
+	{"serverDeleteProhibited", SideServer, CategoryLock, "The registry won't let the domain be deleted"},
+	{"serverHold", SideServer, CategoryHold, "The registry has removed the domain from DNS"},
+	{"serverRenewProhibited", SideServer, CategoryLock, "The registry won't let the domain be renewed"},
+	{"serverTransferProhibited", SideServer, CategoryLock, "The registry won't let the domain be transferred to another registrar"},
+	{"serverUpdateProhibited", SideServer, CategoryLock, "The registry won't let the domain be changed"},
+	{"pendingCreate", "", CategoryPending, "A request to create the domain is being processed"},
+	{"pendingDelete", "", CategoryPending, "The domain is about to be deleted and released"},
+	{"pendingRenew", "", CategoryPending, "A request to renew the domain is being processed"},
+	{"pendingRestore", "", CategoryPending, "A request to restore the deleted domain is being processed"},
+	{"pendingTransfer", "", CategoryPending, "A transfer to another registrar is in progress"},
+	{"pendingUpdate", "", CategoryPending, "A request to change the domain is being processed"},
+	{"addPeriod", "", CategoryPeriod, "Grace period after registration, during which deletion is refunded"},
+	{"autoRenewPeriod", "", CategoryPeriod, "Grace period after automatic renewal, during which deletion is refunded"},
+	{"renewPeriod", "", CategoryPeriod, "Grace period after renewal, during which deletion is refunded"},
+	{"transferPeriod", "", CategoryPeriod, "Grace period after a transfer, during which deletion is refunded"},
+	{"redemptionPeriod", "", CategoryPeriod, "The domain was deleted and can still be restored by the registrar"},
+}
+
+// aliases maps other status names, after compacting, to EPP codes
+var aliases = map[string]string{
+	"active": "ok",
+}
+
+// index maps compacted status names to their Info
+var index = func() map[string]Info {
+	m := make(map[string]Info, len(statuses))
+	for _, info := range statuses {
+		m[compact(info.Code)] = info
+	}
+	for alias, code := range aliases {
+		m[alias] = m[compact(code)]
+	}
+	return m
+}()
+
+// Lookup returns the EPP status for a status value as reported by RDAP
+// ("client transfer prohibited") or WHOIS ("clientTransferProhibited
+// https://icann.org/epp#clientTransferProhibited"). Values that aren't EPP
+// statuses return false.
+func Lookup(value string) (Info, bool) {
+	info, ok := index[compact(stripLinks(value))]
+	return info, ok
+}
+
+// Normalize returns the EPP code for a status value, or the value without
+// any trailing link if it isn't an EPP status
+func Normalize(value string) string {
+	if info, ok := Lookup(value); ok {
+		return info.Code
+	}
+	return stripLinks(value)
+}
+
+// stripLinks removes the links WHOIS servers add after a status, such as
+// "https://icann.org/epp#ok" or "(https://www.icann.org/epp#ok)"
+func stripLinks(value string) string {
+	var words []string
+	for _, word := range strings.Fields(value) {
+		trimmed := strings.TrimLeft(word, "(")
+		if strings.HasPrefix(trimmed, "http://") || strings.HasPrefix(trimmed, "https://") {
+			break
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// compact lowercases a status name and removes separators, so that
+// "client transfer prohibited" and "clientTransferProhibited" match
+func compact(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToLower(value))
+}
@@ -0,0 +1,78 @@
+// Package idn converts internationalized domain names between their
+// Unicode (U-label) and ASCII (A-label, "xn--") forms using IDNA2008 with
+// UTS #46 mapping
+package idn
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// lookupProfile maps user input (case, width, compatibility characters)
+// and validates it for lookups, without the transitional IDNA2003 mapping
+// of characters such as ß
+var lookupProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.ValidateLabels(true),
+	idna.StrictDomainName(true),
+	idna.Transitional(false),
+)
+
+// displayProfile converts A-labels back to Unicode for display
+var displayProfile = idna.New(
+	idna.BidiRule(),
+	idna.ValidateLabels(true),
+	idna.Transitional(false),
+)
+
+// ToASCII converts a domain to its ASCII form, converting any Unicode labels
+// to A-labels and validating existing A-labels
+func ToASCII(domain string) (string, error) {
+	ascii, err := lookupProfile.ToASCII(domain)
+	if err != nil {
+		return "", err
+	}
+
+	// Check that every A-label decodes to a valid U-label
+	if IsIDN(ascii) {
+		if _, err := lookupProfile.ToUnicode(ascii); err != nil {
+			return "", err
+		}
+	}
+
+	return ascii, nil
+}
+
+// ToUnicode converts a domain to its Unicode form. Domains that can't be
+// converted are returned unchanged.
+func ToUnicode(domain string) string {
+	unicode, err := displayProfile.ToUnicode(domain)
+	if err != nil {
+		return domain
+	}
+	return unicode
+}
+
+// IsIDN reports whether a domain contains any A-labels
+func IsIDN(domain string) bool {
+	for _, label := range strings.Split(strings.ToLower(domain), ".") {
+		if strings.HasPrefix(label, "xn--") {
+			return true
+		}
+	}
+	return false
+}
+
+// UnicodeName returns the Unicode form of an ASCII domain, or an empty
+// string if the domain has no A-labels
+func UnicodeName(domain string) string {
+	if !IsIDN(domain) {
+		return ""
+	}
+	if unicode := ToUnicode(domain); unicode != domain {
+		return unicode
+	}
+	return ""
+}
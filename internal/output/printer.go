@@ -4,15 +4,22 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/simplebytes-com/domaindetails-cli/internal/dates"
 	"github.com/simplebytes-com/domaindetails-cli/internal/types"
 )
 
 // Printer handles output formatting
 type Printer struct {
-	jsonOutput bool
-	rawOutput  bool
+	jsonOutput    bool
+	rawOutput     bool
+	showNotices   bool
+	explainStatus bool
 }
 
 // NewPrinter creates a new Printer
@@ -23,6 +30,18 @@ func NewPrinter(jsonOutput, rawOutput bool) *Printer {
 	}
 }
 
+// SetShowNotices sets whether text output includes RDAP notices, remarks,
+// conformance and links (JSON output always does)
+func (p *Printer) SetShowNotices(show bool) {
+	p.showNotices = show
+}
+
+// SetExplainStatus sets whether text output explains each status and
+// summarizes the domain's locks
+func (p *Printer) SetExplainStatus(explain bool) {
+	p.explainStatus = explain
+}
+
 // Print outputs the lookup result
 func (p *Printer) Print(result *types.LookupResult) error {
 	if p.jsonOutput {
@@ -33,20 +52,7 @@ func (p *Printer) Print(result *types.LookupResult) error {
 
 // printJSON outputs the result as JSON
 func (p *Printer) printJSON(result *types.LookupResult) error {
-	output := result
-
-	// Remove raw data if not requested
-	if !p.rawOutput {
-		output = &types.LookupResult{
-			Domain:    result.Domain,
-			Available: result.Available,
-			Method:    result.Method,
-			Message:   result.Message,
-			Parsed:    result.Parsed,
-		}
-	}
-
-	data, err := json.MarshalIndent(output, "", "  ")
+	data, err := json.MarshalIndent(stripRaw(result, p.rawOutput), "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %v", err)
 	}
@@ -55,11 +61,60 @@ func (p *Printer) printJSON(result *types.LookupResult) error {
 	return nil
 }
 
+// stripRaw removes raw data from the result unless it was requested
+func stripRaw(result *types.LookupResult, rawOutput bool) *types.LookupResult {
+	if rawOutput {
+		return result
+	}
+
+	stripped := *result
+	stripped.Raw = ""
+	stripped.RegistrarRaw = ""
+	return &stripped
+}
+
+// NDJSONWriter streams lookup results as newline-delimited JSON. It is safe
+// for concurrent use.
+type NDJSONWriter struct {
+	mu        sync.Mutex
+	enc       *json.Encoder
+	rawOutput bool
+}
+
+// NewNDJSONWriter creates a new NDJSONWriter writing to w
+func NewNDJSONWriter(w io.Writer, rawOutput bool) *NDJSONWriter {
+	return &NDJSONWriter{
+		enc:       json.NewEncoder(w),
+		rawOutput: rawOutput,
+	}
+}
+
+// Write outputs a single result as one line of JSON
+func (w *NDJSONWriter) Write(result *types.LookupResult) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.enc.Encode(stripRaw(result, w.rawOutput)); err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	return nil
+}
+
 // printText outputs the result as formatted text
 func (p *Printer) printText(result *types.LookupResult) error {
 	fmt.Printf("\n%s\n", strings.Repeat("─", 60))
-	fmt.Printf("Domain: %s\n", result.Domain)
+	if result.UnicodeDomain != "" && result.UnicodeDomain != result.Domain {
+		fmt.Printf("Domain: %s (%s)\n", result.UnicodeDomain, result.Domain)
+	} else {
+		fmt.Printf("Domain: %s\n", result.Domain)
+	}
 	fmt.Printf("Method: %s\n", strings.ToUpper(result.Method))
+	if result.CachedAt != "" {
+		fmt.Printf("Cached: %s\n", result.CachedAt)
+	}
+	if result.StrippedSubdomain != "" {
+		fmt.Printf("Query:  %s (stripped subdomain %q)\n", result.Query, result.StrippedSubdomain)
+	}
 	fmt.Printf("%s\n", strings.Repeat("─", 60))
 
 	if result.Available {
@@ -82,6 +137,9 @@ func (p *Printer) printText(result *types.LookupResult) error {
 	if parsed.DomainName != "" {
 		fmt.Printf("\nDomain Name:     %s\n", parsed.DomainName)
 	}
+	if parsed.UnicodeName != "" && !strings.EqualFold(parsed.UnicodeName, parsed.DomainName) {
+		fmt.Printf("Unicode Name:    %s\n", parsed.UnicodeName)
+	}
 
 	// Registrar
 	if parsed.Registrar != "" {
@@ -91,22 +149,35 @@ func (p *Printer) printText(result *types.LookupResult) error {
 	// Registrant
 	if parsed.Registrant != "" {
 		fmt.Printf("Registrant:      %s\n", parsed.Registrant)
+	} else if r, ok := findRedaction(parsed.Redactions, "registrant", "name"); ok {
+		fmt.Printf("Registrant:      %s\n", redactedLabel(r))
 	}
 
 	// Dates
 	fmt.Println()
+	now := time.Now()
 	if parsed.CreationDate != "" {
-		fmt.Printf("Created:         %s\n", formatDate(parsed.CreationDate))
+		fmt.Printf("Created:         %s\n", formatDate(parsed.CreationDate, parsed.CreationTime, now, "registered", "registered"))
 	}
 	if parsed.ExpirationDate != "" {
-		fmt.Printf("Expires:         %s\n", formatDate(parsed.ExpirationDate))
+		fmt.Printf("Expires:         %s\n", formatDate(parsed.ExpirationDate, parsed.ExpirationTime, now, "expires", "expired"))
 	}
 	if parsed.LastModified != "" {
-		fmt.Printf("Last Modified:   %s\n", formatDate(parsed.LastModified))
+		fmt.Printf("Last Modified:   %s\n", formatDate(parsed.LastModified, parsed.LastModifiedTime, now, "updated", "updated"))
+	}
+	if parsed.RegistrarExpirationDate != "" && parsed.RegistrarExpirationDate != parsed.ExpirationDate {
+		fmt.Printf("Registrar Exp.:  %s\n", formatDate(parsed.RegistrarExpirationDate, parsed.RegistrarExpirationTime, now, "expires", "expired"))
+	}
+
+	// Timeline of every event, such as transfers and locks
+	if len(parsed.Events) > 0 {
+		printTimeline(parsed.Events)
 	}
 
 	// Status
-	if len(parsed.Status) > 0 {
+	if p.explainStatus && len(parsed.StatusDetails) > 0 {
+		printStatusDetails(parsed.StatusDetails, parsed.LockPosture)
+	} else if len(parsed.Status) > 0 {
 		fmt.Printf("\nStatus:\n")
 		for _, status := range parsed.Status {
 			fmt.Printf("  • %s\n", status)
@@ -114,39 +185,639 @@ func (p *Printer) printText(result *types.LookupResult) error {
 	}
 
 	// Nameservers
-	if len(parsed.Nameservers) > 0 {
+	if len(parsed.NameserverDetails) > 0 {
+		fmt.Printf("\nNameservers:\n")
+		for _, ns := range parsed.NameserverDetails {
+			fmt.Printf("  • %s\n", nameserverLine(ns))
+		}
+	} else if len(parsed.Nameservers) > 0 {
 		fmt.Printf("\nNameservers:\n")
 		for _, ns := range parsed.Nameservers {
 			fmt.Printf("  • %s\n", ns)
 		}
 	}
 
+	// Contacts
+	if len(parsed.Contacts) > 0 {
+		fmt.Printf("\nContacts:\n")
+		for _, contact := range parsed.Contacts {
+			printContact(contact, parsed.Redactions)
+		}
+	}
+
+	// Redactions
+	if len(parsed.Redactions) > 0 {
+		fmt.Printf("\nRedacted Fields:\n")
+		for _, r := range parsed.Redactions {
+			detail := r.Method
+			if r.Reason != "" {
+				detail += ": " + r.Reason
+			}
+			fmt.Printf("  • %s (%s)\n", r.Field, detail)
+		}
+	}
+
 	// DNSSEC
 	if parsed.DNSSEC != "" {
 		fmt.Printf("\nDNSSEC:          %s\n", parsed.DNSSEC)
 	}
+	if parsed.SecureDNS != nil {
+		printSecureDNS(parsed.SecureDNS)
+	}
 
 	// WHOIS Server (for WHOIS lookups)
 	if parsed.WhoisServer != "" {
 		fmt.Printf("WHOIS Server:    %s\n", parsed.WhoisServer)
 	}
 
+	// Registrar referral (for thin registries)
+	if result.ReferralURL != "" {
+		fmt.Printf("Registrar RDAP:  %s\n", result.ReferralURL)
+	}
+
+	if p.showNotices {
+		printNotices(parsed)
+	}
+
 	fmt.Println()
 
 	// Raw output if requested
 	if p.rawOutput && result.Raw != "" {
+		title := "Raw Response:"
+		if result.RegistrarRaw != "" {
+			title = "Raw Response (registry):"
+		}
 		fmt.Printf("%s\n", strings.Repeat("─", 60))
-		fmt.Printf("Raw Response:\n")
+		fmt.Printf("%s\n", title)
 		fmt.Printf("%s\n", strings.Repeat("─", 60))
 		fmt.Println(result.Raw)
 	}
+	if p.rawOutput && result.RegistrarRaw != "" {
+		fmt.Printf("%s\n", strings.Repeat("─", 60))
+		fmt.Printf("Raw Response (registrar):\n")
+		fmt.Printf("%s\n", strings.Repeat("─", 60))
+		fmt.Println(result.RegistrarRaw)
+	}
+
+	return nil
+}
+
+// formatDate shows a date in UTC with how long ago or from now it is, such
+// as "2024-01-02 00:00:00 UTC (registered 12 years ago)", using the future or
+// past verb. Dates that couldn't be parsed are shown as reported.
+func formatDate(date string, t *time.Time, now time.Time, future, past string) string {
+	if t == nil {
+		parsed, err := dates.Parse(date)
+		if err != nil {
+			return date
+		}
+		t = &parsed
+	}
+
+	verb := past
+	if t.After(now) {
+		verb = future
+	}
+	return fmt.Sprintf("%s (%s %s)", t.UTC().Format("2006-01-02 15:04:05 MST"), verb, dates.Relative(*t, now))
+}
+
+// printStatusDetails prints each status as its EPP code with who set it and
+// what it means, followed by the lock posture
+func printStatusDetails(details []types.StatusDetail, posture *types.LockPosture) {
+	fmt.Printf("\nStatus:\n")
+	for _, detail := range details {
+		kind := detail.Category
+		if detail.Side != "" {
+			kind = detail.Side + " " + kind
+		}
+		fmt.Printf("  • %s [%s]\n", detail.Code, kind)
+		if detail.Description != "" {
+			fmt.Printf("      %s\n", detail.Description)
+		}
+	}
+
+	if posture == nil {
+		return
+	}
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	fmt.Printf("\nRegistrar Lock:  %s\n", yesNo(posture.RegistrarLock))
+	fmt.Printf("Registry Lock:   %s\n", yesNo(posture.RegistryLock))
+	fmt.Printf("On Hold:         %s\n", yesNo(posture.Hold))
+	if len(posture.Pending) > 0 {
+		fmt.Printf("Pending:         %s\n", strings.Join(posture.Pending, ", "))
+	}
+}
+
+// printTimeline prints events oldest first, one per line with its date,
+// action and actor
+func printTimeline(events []types.Event) {
+	fmt.Printf("\nTimeline:\n")
+	for _, event := range events {
+		date := event.Date
+		if event.Time != nil {
+			date = event.Time.UTC().Format("2006-01-02 15:04:05 MST")
+		}
+		line := fmt.Sprintf("%-23s  %s", date, event.Action)
+		if event.Actor != "" {
+			line += " (by " + event.Actor + ")"
+		}
+		fmt.Printf("  • %s\n", line)
+	}
+}
+
+// printContact prints a contact under a heading naming its roles, such as
+// "Registrar abuse" for an abuse contact nested in the registrar entity.
+// Empty fields the server redacted are marked as such.
+func printContact(contact types.Contact, redactions []types.Redaction) {
+	title := strings.Join(contact.Roles, ", ")
+	if contact.Parent != "" {
+		title = strings.Replace(contact.Parent, ",", ", ", -1) + " " + title
+	}
+	if title == "" {
+		title = "contact"
+	}
+	title = strings.ToUpper(title[:1]) + title[1:]
+	if contact.Handle != "" {
+		title += " (" + contact.Handle + ")"
+	}
+	fmt.Printf("  • %s\n", title)
+
+	fields := []struct{ label, value string }{
+		{"Name", contact.Name},
+		{"Organization", contact.Organization},
+		{"Email", contact.Email},
+		{"Phone", contact.Phone},
+		{"Fax", contact.Fax},
+		{"Address", contact.Address},
+		{"Country", contact.Country},
+	}
+	for _, field := range fields {
+		value := field.value
+		if value == "" && contact.Parent == "" {
+			for _, role := range contact.Roles {
+				if r, ok := findRedaction(redactions, role, field.label); ok {
+					value = redactedLabel(r)
+					break
+				}
+			}
+		}
+		if value != "" {
+			fmt.Printf("      %-13s %s\n", field.label+":", value)
+		}
+	}
+}
+
+// redactionFieldWords maps contact fields to the words that identify them
+// in redacted field names, such as "Registrant Street" for the address
+var redactionFieldWords = map[string][]string{
+	"name":         {"name"},
+	"organization": {"organization"},
+	"email":        {"email"},
+	"phone":        {"phone"},
+	"fax":          {"fax"},
+	"address":      {"street", "city", "postal", "address"},
+	"country":      {"country"},
+}
+
+// redactionRoleWords maps entity roles to the words that identify them in
+// redacted field names, such as "Tech Email" for the technical contact
+var redactionRoleWords = map[string]string{
+	"registrant":     "registrant",
+	"administrative": "admin",
+	"technical":      "tech",
+	"billing":        "billing",
+}
+
+// findRedaction finds the redaction of a contact field, such as
+// "Registrant Name" for the registrant's name
+func findRedaction(redactions []types.Redaction, role, field string) (types.Redaction, bool) {
+	roleWord, ok := redactionRoleWords[role]
+	if !ok {
+		return types.Redaction{}, false
+	}
+
+	for _, r := range redactions {
+		name := strings.ToLower(r.Field)
+		if !strings.HasPrefix(name, roleWord) {
+			continue
+		}
+		for _, word := range redactionFieldWords[strings.ToLower(field)] {
+			if strings.Contains(name, word) {
+				return r, true
+			}
+		}
+	}
+	return types.Redaction{}, false
+}
+
+// redactedLabel marks a redacted value, with the reason when given
+func redactedLabel(r types.Redaction) string {
+	if r.Reason != "" {
+		return "[redacted: " + r.Reason + "]"
+	}
+	return "[redacted]"
+}
+
+// PrintNetwork outputs an IP network or autonomous system lookup result
+func (p *Printer) PrintNetwork(result *types.NetworkResult) error {
+	if p.jsonOutput {
+		if !p.rawOutput {
+			stripped := *result
+			stripped.Raw = ""
+			result = &stripped
+		}
+
+		return printIndentedJSON(result)
+	}
+
+	title := "IP Network"
+	if result.ObjectClass == "autnum" {
+		title = "Autonomous System"
+	}
+
+	fmt.Printf("\n%s\n", strings.Repeat("─", 60))
+	fmt.Printf("%s: %s\n", title, result.Query)
+	fmt.Printf("Method: RDAP\n")
+	fmt.Printf("%s\n", strings.Repeat("─", 60))
+
+	fmt.Println()
+	if result.Handle != "" {
+		fmt.Printf("Handle:          %s\n", result.Handle)
+	}
+	if result.Name != "" {
+		fmt.Printf("Name:            %s\n", result.Name)
+	}
+	if result.Type != "" {
+		fmt.Printf("Type:            %s\n", result.Type)
+	}
+	if result.Country != "" {
+		fmt.Printf("Country:         %s\n", result.Country)
+	}
+	if result.StartAddress != "" {
+		fmt.Printf("Range:           %s - %s\n", result.StartAddress, result.EndAddress)
+	}
+	if result.StartAutnum != 0 {
+		fmt.Printf("Range:           AS%d - AS%d\n", result.StartAutnum, result.EndAutnum)
+	}
+	if result.ParentHandle != "" {
+		fmt.Printf("Parent:          %s\n", result.ParentHandle)
+	}
+
+	if len(result.CIDRs) > 0 {
+		fmt.Printf("\nCIDRs:\n")
+		for _, cidr := range result.CIDRs {
+			fmt.Printf("  • %s\n", cidr)
+		}
+	}
+
+	printDates(result.CreationDate, result.LastModified)
+
+	if len(result.Status) > 0 {
+		fmt.Printf("\nStatus:\n")
+		for _, status := range result.Status {
+			fmt.Printf("  • %s\n", status)
+		}
+	}
+
+	if len(result.Contacts) > 0 {
+		fmt.Printf("\nContacts:\n")
+		for _, contact := range result.Contacts {
+			printContact(contact, nil)
+		}
+	}
+
+	if result.Server != "" {
+		fmt.Printf("\nRDAP Server:     %s\n", result.Server)
+	}
+	fmt.Println()
+
+	if p.rawOutput {
+		printRaw(result.Raw)
+	}
+
+	return nil
+}
+
+// PrintNameserver outputs a nameserver lookup result
+func (p *Printer) PrintNameserver(result *types.NameserverResult) error {
+	if p.jsonOutput {
+		if !p.rawOutput {
+			stripped := *result
+			stripped.Raw = ""
+			result = &stripped
+		}
+		return printIndentedJSON(result)
+	}
+
+	fmt.Printf("\n%s\n", strings.Repeat("─", 60))
+	fmt.Printf("Nameserver: %s\n", nameserverLine(types.Nameserver{LDHName: result.LDHName, UnicodeName: result.UnicodeName}))
+	fmt.Printf("Method: RDAP\n")
+	fmt.Printf("%s\n", strings.Repeat("─", 60))
+
+	fmt.Println()
+	if result.Handle != "" {
+		fmt.Printf("Handle:          %s\n", result.Handle)
+	}
+	if len(result.IPv4) == 0 && len(result.IPv6) == 0 {
+		fmt.Printf("IP Addresses:    none registered\n")
+	}
+	if len(result.IPv4) > 0 {
+		fmt.Printf("IPv4:            %s\n", strings.Join(result.IPv4, ", "))
+	}
+	if len(result.IPv6) > 0 {
+		fmt.Printf("IPv6:            %s\n", strings.Join(result.IPv6, ", "))
+	}
+
+	printDates(result.CreationDate, result.LastModified)
+
+	if len(result.Status) > 0 {
+		fmt.Printf("\nStatus:\n")
+		for _, status := range result.Status {
+			fmt.Printf("  • %s\n", status)
+		}
+	}
+
+	if len(result.Contacts) > 0 {
+		fmt.Printf("\nContacts:\n")
+		for _, contact := range result.Contacts {
+			printContact(contact, nil)
+		}
+	}
+
+	fmt.Printf("\nRDAP Server:     %s\n\n", result.Server)
+
+	if p.rawOutput {
+		printRaw(result.Raw)
+	}
+
+	return nil
+}
+
+// PrintEntity outputs an entity lookup result
+func (p *Printer) PrintEntity(result *types.EntityResult) error {
+	if p.jsonOutput {
+		if !p.rawOutput {
+			stripped := *result
+			stripped.Raw = ""
+			result = &stripped
+		}
+		return printIndentedJSON(result)
+	}
+
+	fmt.Printf("\n%s\n", strings.Repeat("─", 60))
+	fmt.Printf("Entity: %s\n", result.Query)
+	fmt.Printf("Method: RDAP\n")
+	fmt.Printf("%s\n", strings.Repeat("─", 60))
+
+	fmt.Println()
+	printContact(result.Contact, nil)
+	if result.Contact.Kind != "" {
+		fmt.Printf("      %-13s %s\n", "Kind:", result.Contact.Kind)
+	}
+
+	printDates(result.CreationDate, result.LastModified)
+
+	if len(result.Status) > 0 {
+		fmt.Printf("\nStatus:\n")
+		for _, status := range result.Status {
+			fmt.Printf("  • %s\n", status)
+		}
+	}
+
+	if len(result.Networks) > 0 {
+		fmt.Printf("\nNetworks:\n")
+		for _, network := range result.Networks {
+			fmt.Printf("  • %s %s (%s)\n", strings.Join(network.CIDRs, ", "), network.Name, network.Handle)
+		}
+	}
+	if len(result.Autnums) > 0 {
+		fmt.Printf("\nAutonomous Systems:\n")
+		for _, autnum := range result.Autnums {
+			asns := fmt.Sprintf("AS%d", autnum.StartAutnum)
+			if autnum.EndAutnum != autnum.StartAutnum {
+				asns += fmt.Sprintf("-AS%d", autnum.EndAutnum)
+			}
+			fmt.Printf("  • %s %s (%s)\n", asns, autnum.Name, autnum.Handle)
+		}
+	}
+	if len(result.Domains) > 0 {
+		fmt.Printf("\nDomains:\n")
+		for _, domain := range result.Domains {
+			fmt.Printf("  • %s\n", domain)
+		}
+	}
+
+	if len(result.Contacts) > 0 {
+		fmt.Printf("\nContacts:\n")
+		for _, contact := range result.Contacts {
+			printContact(contact, nil)
+		}
+	}
+
+	fmt.Printf("\nRDAP Server:     %s\n\n", result.Server)
+
+	if p.rawOutput {
+		printRaw(result.Raw)
+	}
+
+	return nil
+}
+
+// printSecureDNS prints a domain's DS and DNSKEY records
+func printSecureDNS(secureDNS *types.SecureDNS) {
+	if secureDNS.MaxSigLife > 0 {
+		fmt.Printf("Max Sig. Life:   %ds\n", secureDNS.MaxSigLife)
+	}
+
+	if len(secureDNS.DSData) > 0 {
+		fmt.Printf("DS Records:\n")
+		for _, ds := range secureDNS.DSData {
+			fmt.Printf("  • Key tag %d, algorithm %s, digest type %s\n", ds.KeyTag,
+				numberName(ds.Algorithm, ds.AlgorithmName), numberName(ds.DigestType, ds.DigestTypeName))
+			fmt.Printf("      %s\n", ds.Digest)
+		}
+	}
+
+	if len(secureDNS.KeyData) > 0 {
+		fmt.Printf("DNSKEY Records:\n")
+		for _, key := range secureDNS.KeyData {
+			fmt.Printf("  • Flags %d, protocol %d, algorithm %s\n", key.Flags, key.Protocol,
+				numberName(key.Algorithm, key.AlgorithmName))
+			fmt.Printf("      %s\n", key.PublicKey)
+		}
+	}
+}
+
+// numberName formats a registry number with its name, such as
+// "13 (ECDSAP256SHA256)"
+func numberName(number int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("%d", number)
+	}
+	return fmt.Sprintf("%d (%s)", number, name)
+}
+
+// printNotices prints the RDAP conformance, notices, remarks and links of
+// parsed data
+func printNotices(parsed *types.ParsedData) {
+	if len(parsed.Conformance) > 0 {
+		fmt.Printf("\nRDAP Conformance:\n")
+		for _, c := range parsed.Conformance {
+			fmt.Printf("  • %s\n", c)
+		}
+	}
+
+	sections := []struct {
+		title   string
+		notices []types.Notice
+	}{
+		{"Notices", parsed.Notices},
+		{"Remarks", parsed.Remarks},
+	}
+	for _, section := range sections {
+		if len(section.notices) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", section.title)
+		for _, notice := range section.notices {
+			title, description := notice.Title, notice.Description
+			if title == "" {
+				title = notice.Type
+			}
+			if title == "" && len(description) > 0 {
+				title, description = description[0], description[1:]
+			}
+			fmt.Printf("  • %s\n", title)
+			for _, line := range description {
+				fmt.Printf("      %s\n", line)
+			}
+			for _, link := range notice.Links {
+				fmt.Printf("      → %s\n", link.Href)
+			}
+		}
+	}
+
+	if len(parsed.Links) > 0 {
+		fmt.Printf("\nLinks:\n")
+		for _, link := range parsed.Links {
+			if link.Rel != "" {
+				fmt.Printf("  • %s: %s\n", link.Rel, link.Href)
+			} else {
+				fmt.Printf("  • %s\n", link.Href)
+			}
+		}
+	}
+}
+
+// nameserverLine formats a nameserver with its Unicode name and any glue
+// addresses
+func nameserverLine(ns types.Nameserver) string {
+	line := ns.LDHName
+	if ns.UnicodeName != "" && !strings.EqualFold(ns.UnicodeName, ns.LDHName) {
+		line += " (" + ns.UnicodeName + ")"
+	}
+
+	addrs := append(append([]string(nil), ns.IPv4...), ns.IPv6...)
+	if len(addrs) > 0 {
+		line += " [" + strings.Join(addrs, ", ") + "]"
+	}
+	return line
+}
+
+// printDates prints an object's registration and last changed dates
+func printDates(created, changed string) {
+	if created == "" && changed == "" {
+		return
+	}
+
+	now := time.Now()
+	fmt.Println()
+	if created != "" {
+		fmt.Printf("Created:         %s\n", formatDate(created, nil, now, "registered", "registered"))
+	}
+	if changed != "" {
+		fmt.Printf("Last Modified:   %s\n", formatDate(changed, nil, now, "updated", "updated"))
+	}
+}
+
+// printRaw prints a raw response section
+func printRaw(raw string) {
+	if raw == "" {
+		return
+	}
+	fmt.Printf("%s\n", strings.Repeat("─", 60))
+	fmt.Printf("Raw Response:\n")
+	fmt.Printf("%s\n", strings.Repeat("─", 60))
+	fmt.Println(raw)
+}
 
+func printIndentedJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	fmt.Println(string(data))
 	return nil
 }
 
-// formatDate attempts to format a date string nicely
-func formatDate(date string) string {
-	// Just return the date as-is for now
-	// Could enhance with time.Parse to reformat
-	return date
+// PrintError outputs why a command failed: as a JSON object with an "error"
+// member on stdout, or as a message on stderr
+func (p *Printer) PrintError(result *types.ErrorResult) error {
+	if p.jsonOutput {
+		return printIndentedJSON(struct {
+			Error *types.ErrorResult `json:"error"`
+		}{result})
+	}
+	fmt.Fprintf(os.Stderr, "Error: %s\n", result.Message)
+	return nil
+}
+
+// PrintSearch outputs the matches of an RDAP search
+func (p *Printer) PrintSearch(result *types.SearchResult) error {
+	if p.jsonOutput {
+		return printIndentedJSON(result)
+	}
+
+	fmt.Printf("\n%s\n", strings.Repeat("─", 60))
+	fmt.Printf("Search: %s\n", result.Query)
+	fmt.Printf("Server: %s\n", result.Server)
+	fmt.Printf("%s\n", strings.Repeat("─", 60))
+
+	count := len(result.Domains) + len(result.Nameservers)
+	if count == 0 {
+		fmt.Printf("\nNo matches found\n\n")
+		return nil
+	}
+
+	fmt.Println()
+	for _, domain := range result.Domains {
+		line := domain.LDHName
+		if domain.UnicodeName != "" && !strings.EqualFold(domain.UnicodeName, domain.LDHName) {
+			line += " (" + domain.UnicodeName + ")"
+		}
+		if len(domain.Status) > 0 {
+			line += "  [" + strings.Join(domain.Status, ", ") + "]"
+		}
+		fmt.Printf("  • %s\n", line)
+	}
+	for _, ns := range result.Nameservers {
+		fmt.Printf("  • %s\n", nameserverLine(ns))
+	}
+
+	fmt.Printf("\n%d matches", count)
+	if result.TotalCount > count {
+		fmt.Printf(" of %d", result.TotalCount)
+	}
+	fmt.Printf(" (%d pages)\n", result.Pages)
+	if result.Truncated {
+		fmt.Printf("Results truncated: %s\n", result.TruncatedReason)
+	}
+	fmt.Println()
+
+	return nil
 }
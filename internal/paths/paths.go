@@ -0,0 +1,71 @@
+// Package paths resolves where the CLI keeps its cache and configuration,
+// following the XDG base directory conventions
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AppName is the directory name used under the cache and config base
+// directories
+const AppName = "domaindetails"
+
+// LegacyDir is the directory under the home directory used before the XDG
+// locations, whose contents are migrated to the cache directory
+const LegacyDir = ".domaindetails"
+
+// Environment variables overriding the default locations
+const (
+	CacheDirEnv  = "DOMAINDETAILS_CACHE_DIR"
+	ConfigDirEnv = "DOMAINDETAILS_CONFIG_DIR"
+)
+
+// CacheDir returns the cache directory: $DOMAINDETAILS_CACHE_DIR, else
+// domaindetails under $XDG_CACHE_HOME or the platform's user cache
+// directory. It falls back to the temporary directory rather than the
+// working directory when neither is known.
+func CacheDir() string {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, AppName)
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, AppName)
+	}
+	return filepath.Join(os.TempDir(), AppName)
+}
+
+// ConfigDir returns the configuration directory: $DOMAINDETAILS_CONFIG_DIR,
+// else domaindetails under $XDG_CONFIG_HOME or the platform's user config
+// directory. It is empty when none of them is known.
+func ConfigDir() string {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, AppName)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, AppName)
+	}
+	return ""
+}
+
+// IsDefaultCacheDir reports whether the cache directory comes from the
+// XDG or platform defaults rather than an override
+func IsDefaultCacheDir() bool {
+	return os.Getenv(CacheDirEnv) == ""
+}
+
+// LegacyCacheDir returns the old ~/.domaindetails directory, or an empty
+// string when there is no home directory
+func LegacyCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, LegacyDir)
+}
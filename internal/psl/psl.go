@@ -0,0 +1,197 @@
+// Package psl implements Public Suffix List matching, used to find the
+// registrable part of a domain and the suffixes worth trying in the RDAP
+// bootstrap
+package psl
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+)
+
+// snapshot is the Public Suffix List shipped with the binary, used when no
+// refreshed copy is available in the cache
+//
+//go:embed public_suffix_list.dat
+var snapshot []byte
+
+// List is a parsed Public Suffix List. Only the ICANN section is used:
+// private suffixes such as github.io are not registry boundaries, so they
+// don't change where a domain's registration data lives.
+type List struct {
+	rules      map[string]bool
+	wildcards  map[string]bool
+	exceptions map[string]bool
+}
+
+var (
+	embeddedOnce sync.Once
+	embedded     *List
+)
+
+// Parse parses Public Suffix List data
+func Parse(data []byte) (*List, error) {
+	l := &List{
+		rules:      make(map[string]bool),
+		wildcards:  make(map[string]bool),
+		exceptions: make(map[string]bool),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "// ===BEGIN PRIVATE DOMAINS===") {
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		// Rules end at the first whitespace
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.ToLower(line)
+
+		rules := l.rules
+		switch {
+		case strings.HasPrefix(line, "!"):
+			rules, line = l.exceptions, line[1:]
+		case strings.HasPrefix(line, "*."):
+			rules, line = l.wildcards, line[2:]
+		}
+
+		// Lookups use A-labels, so internationalized rules are
+		// converted to match them
+		if !isASCII(line) {
+			ascii, err := idn.ToASCII(line)
+			if err != nil {
+				continue
+			}
+			line = ascii
+		}
+
+		rules[line] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(l.rules) == 0 {
+		return nil, fmt.Errorf("no public suffix rules found")
+	}
+
+	return l, nil
+}
+
+// Embedded returns the list shipped with the binary
+func Embedded() *List {
+	embeddedOnce.Do(func() {
+		l, err := Parse(snapshot)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded public suffix list: %v", err))
+		}
+		embedded = l
+	})
+	return embedded
+}
+
+// Load returns the cached Public Suffix List, refreshing it when it has
+// expired, and falls back to the embedded snapshot when no usable copy can
+// be read or fetched
+func Load(c *cache.Cache) *List {
+	data, err := c.GetPublicSuffixList()
+	if err != nil {
+		return Embedded()
+	}
+
+	l, err := Parse(data)
+	if err != nil {
+		return Embedded()
+	}
+
+	return l
+}
+
+// PublicSuffix returns the public suffix of a domain, such as "co.uk" for
+// "www.example.co.uk". Domains without a matching rule use their last label.
+// Internationalized suffixes are returned as A-labels.
+func (l *List) PublicSuffix(domain string) string {
+	labels := strings.Split(normalize(domain), ".")
+
+	// The first match from the left is the longest one
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+
+		if l.exceptions[suffix] {
+			return strings.Join(labels[i+1:], ".")
+		}
+		if l.rules[suffix] {
+			return suffix
+		}
+		if i+1 < len(labels) && l.wildcards[strings.Join(labels[i+1:], ".")] {
+			return suffix
+		}
+	}
+
+	return labels[len(labels)-1]
+}
+
+// RegistrableDomain returns the public suffix of a domain plus one label,
+// such as "example.co.uk" for "www.example.co.uk"
+func (l *List) RegistrableDomain(domain string) (string, error) {
+	domain = normalize(domain)
+	suffix := l.PublicSuffix(domain)
+
+	if domain == suffix {
+		return "", fmt.Errorf("%s is a public suffix", domain)
+	}
+
+	rest := strings.TrimSuffix(domain, "."+suffix)
+	if i := strings.LastIndex(rest, "."); i >= 0 {
+		rest = rest[i+1:]
+	}
+
+	return rest + "." + suffix, nil
+}
+
+// Suffixes returns the domain's public suffix and each of its parent
+// suffixes, longest first: "co.jp" and "jp" for "www.example.co.jp"
+func (l *List) Suffixes(domain string) []string {
+	suffix := l.PublicSuffix(domain)
+	labels := strings.Split(suffix, ".")
+
+	suffixes := make([]string, 0, len(labels))
+	for i := range labels {
+		suffixes = append(suffixes, strings.Join(labels[i:], "."))
+	}
+	return suffixes
+}
+
+// normalize lowercases a domain and converts it to A-labels, the form the
+// rules are kept in
+func normalize(domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if !isASCII(domain) {
+		if ascii, err := idn.ToASCII(domain); err == nil {
+			return ascii
+		}
+	}
+	return domain
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
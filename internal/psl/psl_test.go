@@ -0,0 +1,106 @@
+package psl
+
+import "testing"
+
+// testList is a small list covering each kind of rule
+const testList = `// ===BEGIN ICANN DOMAINS===
+uk
+co.uk
+jp
+co.jp
+cn
+公司.cn
+*.ck
+!www.ck
+// ===END ICANN DOMAINS===
+// ===BEGIN PRIVATE DOMAINS===
+github.io
+// ===END PRIVATE DOMAINS===
+`
+
+func parseTestList(t *testing.T) *List {
+	t.Helper()
+	l, err := Parse([]byte(testList))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return l
+}
+
+func TestPublicSuffix(t *testing.T) {
+	l := parseTestList(t)
+	tests := map[string]string{
+		"example.co.uk":     "co.uk",
+		"www.example.co.uk": "co.uk",
+		"Example.CO.UK.":    "co.uk",
+		"example.uk":        "uk",
+		"example.jp":        "jp",
+		"example.unlisted":  "unlisted",
+		"example.github.io": "io",
+		// Wildcard: every name under ck is a suffix...
+		"example.gov.ck":     "gov.ck",
+		"www.example.gov.ck": "gov.ck",
+		// ...except the one named by the exception
+		"www.ck":                "ck",
+		"sub.www.ck":            "ck",
+		"example.公司.cn":         "xn--55qx5d.cn",
+		"example.xn--55qx5d.cn": "xn--55qx5d.cn",
+		"example.cn":            "cn",
+	}
+	for domain, want := range tests {
+		if got := l.PublicSuffix(domain); got != want {
+			t.Errorf("PublicSuffix(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	l := parseTestList(t)
+	tests := map[string]string{
+		"www.example.co.uk":         "example.co.uk",
+		"example.co.uk":             "example.co.uk",
+		"a.b.example.gov.ck":        "example.gov.ck",
+		"www.ck":                    "www.ck",
+		"sub.www.ck":                "www.ck",
+		"www.example.公司.cn":         "example.xn--55qx5d.cn",
+		"www.example.xn--55qx5d.cn": "example.xn--55qx5d.cn",
+	}
+	for domain, want := range tests {
+		got, err := l.RegistrableDomain(domain)
+		if err != nil {
+			t.Errorf("RegistrableDomain(%q) error: %v", domain, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", domain, got, want)
+		}
+	}
+
+	for _, suffix := range []string{"co.uk", "gov.ck", "公司.cn"} {
+		if got, err := l.RegistrableDomain(suffix); err == nil {
+			t.Errorf("RegistrableDomain(%q) = %q, want an error for a public suffix", suffix, got)
+		}
+	}
+}
+
+func TestSuffixes(t *testing.T) {
+	l := parseTestList(t)
+	got := l.Suffixes("www.example.co.jp")
+	if len(got) != 2 || got[0] != "co.jp" || got[1] != "jp" {
+		t.Errorf("Suffixes = %q, want [co.jp jp]", got)
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	l := Embedded()
+	tests := map[string]string{
+		"www.example.co.uk": "co.uk",
+		"example.gov.ck":    "gov.ck",
+		"www.ck":            "ck",
+	}
+	for domain, want := range tests {
+		if got := l.PublicSuffix(domain); got != want {
+			t.Errorf("PublicSuffix(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
@@ -3,13 +3,18 @@ package rdap
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/simplebytes-com/domaindetails-cli/internal/cache"
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/simplebytes-com/domaindetails-cli/internal/psl"
 	"github.com/simplebytes-com/domaindetails-cli/internal/types"
 )
 
@@ -21,11 +26,20 @@ const (
 	UserAgent = "domaindetails-cli/1.0 (https://domaindetails.com)"
 )
 
-// Client performs RDAP lookups
+// Client performs RDAP lookups. It is safe for concurrent use, and requests
+// to the same RDAP server share a single rate limit.
 type Client struct {
-	cache   *cache.Cache
-	verbose bool
-	client  *http.Client
+	cache      *cache.Cache
+	verbose    bool
+	client     *http.Client
+	limiter    *hostLimiter
+	maxRetries int
+	userAgent  string
+
+	followReferrals bool
+
+	pslOnce sync.Once
+	psl     *psl.List
 }
 
 // NewClient creates a new RDAP client
@@ -36,26 +50,65 @@ func NewClient(verbose bool) *Client {
 		client: &http.Client{
 			Timeout: RequestTimeout,
 		},
+		limiter:    newHostLimiter(DefaultRateLimit, int(DefaultRateLimit)),
+		maxRetries: DefaultMaxRetries,
+		userAgent:  UserAgent,
+
+		followReferrals: true,
+	}
+}
+
+// SetFollowReferrals sets whether registrar RDAP referrals found in registry
+// responses are followed (enabled by default)
+func (c *Client) SetFollowReferrals(follow bool) {
+	c.followReferrals = follow
+}
+
+// SetRateLimit sets the number of requests per second allowed per RDAP
+// server and the burst size. A rate of zero or less disables limiting.
+func (c *Client) SetRateLimit(rate float64, burst int) {
+	c.limiter = newHostLimiter(rate, burst)
+}
+
+// SetMaxRetries sets how many times a throttled or temporarily unavailable
+// request is retried
+func (c *Client) SetMaxRetries(n int) {
+	if n < 0 {
+		n = 0
 	}
+	c.maxRetries = n
+}
+
+// SetTimeout sets the timeout for each RDAP request
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.client.Timeout = timeout
+}
+
+// SetUserAgent sets the User-Agent sent to RDAP servers
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
 }
 
 // RDAPResponse represents the raw RDAP response
 type RDAPResponse struct {
-	ObjectClassName string            `json:"objectClassName"`
-	LDHName         string            `json:"ldhName"`
-	UnicodeName     string            `json:"unicodeName,omitempty"`
-	Handle          string            `json:"handle,omitempty"`
-	Status          []string          `json:"status,omitempty"`
-	Events          []RDAPEvent       `json:"events,omitempty"`
-	Entities        []RDAPEntity      `json:"entities,omitempty"`
-	Nameservers     []RDAPNameserver  `json:"nameservers,omitempty"`
-	SecureDNS       *RDAPSecureDNS    `json:"secureDNS,omitempty"`
-	Links           []RDAPLink        `json:"links,omitempty"`
-	Remarks         []RDAPRemark      `json:"remarks,omitempty"`
-	Port43          string            `json:"port43,omitempty"`
-	ErrorCode       int               `json:"errorCode,omitempty"`
-	Title           string            `json:"title,omitempty"`
-	Description     []string          `json:"description,omitempty"`
+	Conformance     []string         `json:"rdapConformance,omitempty"`
+	Notices         []RDAPRemark     `json:"notices,omitempty"`
+	ObjectClassName string           `json:"objectClassName"`
+	LDHName         string           `json:"ldhName"`
+	UnicodeName     string           `json:"unicodeName,omitempty"`
+	Handle          string           `json:"handle,omitempty"`
+	Status          []string         `json:"status,omitempty"`
+	Events          []RDAPEvent      `json:"events,omitempty"`
+	Entities        []RDAPEntity     `json:"entities,omitempty"`
+	Nameservers     []RDAPNameserver `json:"nameservers,omitempty"`
+	SecureDNS       *RDAPSecureDNS   `json:"secureDNS,omitempty"`
+	Links           []RDAPLink       `json:"links,omitempty"`
+	Remarks         []RDAPRemark     `json:"remarks,omitempty"`
+	Redacted        []RDAPRedaction  `json:"redacted,omitempty"`
+	Port43          string           `json:"port43,omitempty"`
+	ErrorCode       int              `json:"errorCode,omitempty"`
+	Title           string           `json:"title,omitempty"`
+	Description     []string         `json:"description,omitempty"`
 }
 
 // RDAPEvent represents an RDAP event
@@ -67,84 +120,83 @@ type RDAPEvent struct {
 
 // RDAPEntity represents an RDAP entity
 type RDAPEntity struct {
-	Handle     string          `json:"handle,omitempty"`
-	Roles      []string        `json:"roles,omitempty"`
-	VCardArray json.RawMessage `json:"vcardArray,omitempty"`
-	Entities   []RDAPEntity    `json:"entities,omitempty"`
+	ObjectClassName string          `json:"objectClassName,omitempty"`
+	Handle          string          `json:"handle,omitempty"`
+	Roles           []string        `json:"roles,omitempty"`
+	VCardArray      json.RawMessage `json:"vcardArray,omitempty"`
+	Entities        []RDAPEntity    `json:"entities,omitempty"`
+	Status          []string        `json:"status,omitempty"`
+	Events          []RDAPEvent     `json:"events,omitempty"`
+	Links           []RDAPLink      `json:"links,omitempty"`
+	Networks        []RDAPIPNetwork `json:"networks,omitempty"`
+	Autnums         []RDAPAutnum    `json:"autnums,omitempty"`
 }
 
 // RDAPNameserver represents an RDAP nameserver
 type RDAPNameserver struct {
-	LDHName     string `json:"ldhName"`
-	UnicodeName string `json:"unicodeName,omitempty"`
+	ObjectClassName string           `json:"objectClassName,omitempty"`
+	Handle          string           `json:"handle,omitempty"`
+	LDHName         string           `json:"ldhName"`
+	UnicodeName     string           `json:"unicodeName,omitempty"`
+	IPAddresses     *RDAPIPAddresses `json:"ipAddresses,omitempty"`
+	Status          []string         `json:"status,omitempty"`
+	Events          []RDAPEvent      `json:"events,omitempty"`
+	Entities        []RDAPEntity     `json:"entities,omitempty"`
+}
+
+// RDAPIPAddresses holds a nameserver's addresses (glue records)
+type RDAPIPAddresses struct {
+	V4 []string `json:"v4,omitempty"`
+	V6 []string `json:"v6,omitempty"`
 }
 
 // RDAPSecureDNS represents DNSSEC information
 type RDAPSecureDNS struct {
-	DelegationSigned bool `json:"delegationSigned"`
+	ZoneSigned       *bool         `json:"zoneSigned,omitempty"`
+	DelegationSigned bool          `json:"delegationSigned"`
+	MaxSigLife       int           `json:"maxSigLife,omitempty"`
+	DSData           []RDAPDSData  `json:"dsData,omitempty"`
+	KeyData          []RDAPKeyData `json:"keyData,omitempty"`
 }
 
 // RDAPLink represents an RDAP link
 type RDAPLink struct {
-	Rel  string `json:"rel"`
-	Href string `json:"href"`
-	Type string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+	Rel   string `json:"rel"`
+	Href  string `json:"href"`
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"`
 }
 
-// RDAPRemark represents an RDAP remark
+// RDAPRemark represents an RDAP remark or notice
 type RDAPRemark struct {
-	Title       string   `json:"title,omitempty"`
-	Description []string `json:"description,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	Description []string   `json:"description,omitempty"`
+	Links       []RDAPLink `json:"links,omitempty"`
 }
 
 // Lookup performs an RDAP lookup for the given domain
 func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
-	// Extract TLD
-	tld := extractTLD(domain)
-
-	if c.verbose {
-		fmt.Printf("Extracting TLD: %s\n", tld)
-	}
-
-	// Get RDAP server from cache
-	serverURL, err := c.cache.GetRDAPServer(tld)
+	// Find the RDAP server for the longest matching bootstrap entry
+	tld, servers, err := c.findServers(domain)
 	if err != nil {
-		return nil, fmt.Errorf("no RDAP server for TLD .%s: %v", tld, err)
+		return nil, err
 	}
 
 	if c.verbose {
-		fmt.Printf("Using RDAP server: %s\n", serverURL)
-	}
-
-	// Build query URL
-	queryURL := fmt.Sprintf("%sdomain/%s", serverURL, domain)
-
-	if c.verbose {
-		fmt.Printf("Querying: %s\n", queryURL)
+		fmt.Printf("Using bootstrap TLD: %s\n", tld)
+		fmt.Printf("Using RDAP servers: %s\n", strings.Join(servers, ", "))
 	}
 
-	// Make request
-	req, err := http.NewRequest("GET", queryURL, nil)
+	serverURL, status, body, err := c.getFromServers(servers, "domain/"+domain)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Accept", "application/rdap+json, application/json")
-	req.Header.Set("User-Agent", UserAgent)
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
+		return nil, err
 	}
+	queryURL := serverURL + "domain/" + domain
 
 	// Check for errors
-	if resp.StatusCode == 404 {
+	if status == 404 {
 		return &types.LookupResult{
 			Domain:    domain,
 			Available: true,
@@ -153,8 +205,8 @@ func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
 		}, nil
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("RDAP server returned status %d", resp.StatusCode)
+	if status != 200 {
+		return nil, &StatusError{Server: serverHost(serverURL), StatusCode: status}
 	}
 
 	// Parse response
@@ -166,14 +218,87 @@ func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
 	// Convert to common result format
 	result := c.convertToResult(domain, &rdapResp, body)
 
+	// Thin registries only hold part of the data; the rest lives with the
+	// registrar, which the registry points at with a "related" link
+	if c.followReferrals {
+		if referral := findRegistrarReferral(&rdapResp, queryURL); referral != "" {
+			c.followReferral(result, referral)
+		}
+	}
+
 	return result, nil
 }
 
+// get performs a rate-limited GET request against an RDAP server, retrying
+// throttled (429) and temporarily unavailable (502/503/504) responses with
+// exponential backoff, honoring any Retry-After header
+func (c *Client) get(queryURL string) (int, []byte, error) {
+	u, err := url.Parse(queryURL)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid URL %s: %v", queryURL, err)
+	}
+	host := u.Host
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", queryURL, nil)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Set("Accept", "application/rdap+json, application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+
+		c.limiter.Wait(host)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return 0, nil, &RequestError{Server: host, Err: fmt.Errorf("request failed: %w", err)}
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, nil, &RequestError{Server: host, Err: fmt.Errorf("failed to read response: %w", err)}
+		}
+
+		if !isRetryableStatus(resp.StatusCode) {
+			return resp.StatusCode, body, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if attempt >= c.maxRetries || retryAfter > MaxRetryAfter {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				return 0, nil, &StatusError{Server: host, StatusCode: resp.StatusCode}
+			}
+			return resp.StatusCode, body, nil
+		}
+
+		delay := retryAfter
+		if delay > 0 {
+			// The server told us when to come back, so hold off every
+			// request to this host, not just this one
+			c.limiter.Pause(host, delay)
+		} else {
+			delay = backoff(attempt)
+		}
+
+		if c.verbose {
+			fmt.Printf("RDAP server %s returned status %d, retrying in %s\n", host, resp.StatusCode, delay.Round(time.Millisecond))
+		}
+
+		time.Sleep(delay)
+	}
+}
+
 // convertToResult converts RDAP response to common result format
 func (c *Client) convertToResult(domain string, resp *RDAPResponse, raw []byte) *types.LookupResult {
 	parsed := types.ParsedData{
-		DomainName: resp.LDHName,
-		Status:     resp.Status,
+		DomainName:  resp.LDHName,
+		UnicodeName: resp.UnicodeName,
+		Status:      resp.Status,
+	}
+	if parsed.UnicodeName == "" {
+		parsed.UnicodeName = idn.UnicodeName(resp.LDHName)
 	}
 
 	// Extract dates from events
@@ -187,13 +312,16 @@ func (c *Client) convertToResult(domain string, resp *RDAPResponse, raw []byte)
 			parsed.LastModified = event.EventDate
 		}
 	}
+	parsed.Events = convertEvents(resp.Events)
 
-	// Extract nameservers
+	// Extract nameservers, with any glue addresses
 	for _, ns := range resp.Nameservers {
 		parsed.Nameservers = append(parsed.Nameservers, ns.LDHName)
+		parsed.NameserverDetails = append(parsed.NameserverDetails, convertNameserver(ns))
 	}
 
-	// Extract registrar
+	// Extract contacts, and the registrar and registrant names
+	parsed.Contacts = collectContacts(resp.Entities, nil)
 	for _, entity := range resp.Entities {
 		for _, role := range entity.Roles {
 			if role == "registrar" {
@@ -205,6 +333,14 @@ func (c *Client) convertToResult(domain string, resp *RDAPResponse, raw []byte)
 		}
 	}
 
+	parsed.Redactions = convertRedactions(resp.Redacted)
+
+	// Notices, remarks, conformance and links
+	parsed.Conformance = resp.Conformance
+	parsed.Notices = convertNotices(resp.Notices)
+	parsed.Remarks = convertNotices(resp.Remarks)
+	parsed.Links = convertLinks(resp.Links)
+
 	// DNSSEC
 	if resp.SecureDNS != nil {
 		if resp.SecureDNS.DelegationSigned {
@@ -212,7 +348,11 @@ func (c *Client) convertToResult(domain string, resp *RDAPResponse, raw []byte)
 		} else {
 			parsed.DNSSEC = "unsigned"
 		}
+		parsed.SecureDNS = convertSecureDNS(resp.SecureDNS)
 	}
+	parsed.NormalizeDates()
+	parsed.NormalizeStatus()
+	sortEvents(parsed.Events)
 
 	return &types.LookupResult{
 		Domain:    domain,
@@ -223,56 +363,76 @@ func (c *Client) convertToResult(domain string, resp *RDAPResponse, raw []byte)
 	}
 }
 
-// extractTLD extracts the TLD from a domain
-func extractTLD(domain string) string {
-	parts := strings.Split(domain, ".")
-	if len(parts) < 2 {
-		return ""
-	}
-
-	// Check for common second-level TLDs
-	secondLevel := map[string]bool{
-		"co.uk": true, "org.uk": true, "me.uk": true,
-		"com.au": true, "net.au": true, "org.au": true,
-		"co.nz": true, "net.nz": true, "org.nz": true,
-		"co.za": true, "co.in": true, "com.br": true,
+// findServer returns the preferred RDAP server for a domain
+func (c *Client) findServer(domain string) (string, string, error) {
+	suffix, servers, err := c.findServers(domain)
+	if err != nil {
+		return "", "", err
 	}
+	return suffix, servers[0], nil
+}
 
-	if len(parts) >= 3 {
-		twoLevel := parts[len(parts)-2] + "." + parts[len(parts)-1]
-		if secondLevel[twoLevel] {
-			return twoLevel
+// findServers returns the RDAP servers for a domain, preferred first,
+// trying its public suffix and each parent suffix in turn so that the
+// longest bootstrap entry wins
+func (c *Client) findServers(domain string) (string, []string, error) {
+	c.pslOnce.Do(func() {
+		c.psl = psl.Load(c.cache)
+	})
+
+	suffixes := c.psl.Suffixes(domain)
+
+	tld := suffixes[len(suffixes)-1]
+	for _, suffix := range suffixes {
+		servers, err := c.cache.GetRDAPServers(suffix)
+		if err == nil {
+			return suffix, servers, nil
+		}
+		// Bootstrap fetch and cache errors aren't a missing server
+		if !errors.Is(err, cache.ErrNoRDAPServer) {
+			return "", nil, err
 		}
 	}
 
-	return parts[len(parts)-1]
+	return "", nil, fmt.Errorf("%w for TLD .%s", cache.ErrNoRDAPServer, tld)
 }
 
-// extractEntityName tries to extract a name from an RDAP entity
-func extractEntityName(entity RDAPEntity) string {
-	if entity.Handle != "" {
-		return entity.Handle
-	}
+// getFromServers requests path from each server in turn, moving on to the
+// next when one can't be reached or fails with a 5xx status. It returns the
+// server that answered.
+func (c *Client) getFromServers(servers []string, path string) (string, int, []byte, error) {
+	var lastErr error
+	for i, serverURL := range servers {
+		queryURL := serverURL + path
+		if c.verbose {
+			fmt.Printf("Querying: %s\n", queryURL)
+		}
 
-	// Try to parse vCard if available
-	if len(entity.VCardArray) > 0 {
-		var vcard []interface{}
-		if err := json.Unmarshal(entity.VCardArray, &vcard); err == nil {
-			if len(vcard) >= 2 {
-				if fields, ok := vcard[1].([]interface{}); ok {
-					for _, field := range fields {
-						if f, ok := field.([]interface{}); ok && len(f) >= 4 {
-							if f[0] == "fn" {
-								if name, ok := f[3].(string); ok {
-									return name
-								}
-							}
-						}
-					}
-				}
-			}
+		status, body, err := c.get(queryURL)
+		if err == nil && status < 500 {
+			return serverURL, status, body, nil
+		}
+		if err == nil {
+			err = &StatusError{Server: serverHost(serverURL), StatusCode: status}
+		}
+		lastErr = err
+
+		if c.verbose && i < len(servers)-1 {
+			fmt.Printf("%s failed (%v), trying the next server\n", serverURL, err)
 		}
 	}
+	return "", 0, nil, lastErr
+}
 
-	return ""
+// extractEntityName returns the name of an RDAP entity from its vCard,
+// falling back to its organization and then its handle
+func extractEntityName(entity RDAPEntity) string {
+	contact := contactFromEntity(entity)
+	switch {
+	case contact.Name != "":
+		return contact.Name
+	case contact.Organization != "":
+		return contact.Organization
+	}
+	return entity.Handle
 }
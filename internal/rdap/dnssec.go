@@ -0,0 +1,84 @@
+package rdap
+
+import (
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// RDAPDSData is a DS record of the secureDNS member
+type RDAPDSData struct {
+	KeyTag     int    `json:"keyTag"`
+	Algorithm  int    `json:"algorithm"`
+	Digest     string `json:"digest"`
+	DigestType int    `json:"digestType"`
+}
+
+// RDAPKeyData is a DNSKEY record of the secureDNS member
+type RDAPKeyData struct {
+	Flags     int    `json:"flags"`
+	Protocol  int    `json:"protocol"`
+	PublicKey string `json:"publicKey"`
+	Algorithm int    `json:"algorithm"`
+}
+
+// dnssecAlgorithms are the DNSSEC algorithm mnemonics from the IANA
+// "DNS Security Algorithm Numbers" registry
+var dnssecAlgorithms = map[int]string{
+	1:  "RSAMD5",
+	3:  "DSA",
+	5:  "RSASHA1",
+	6:  "DSA-NSEC3-SHA1",
+	7:  "RSASHA1-NSEC3-SHA1",
+	8:  "RSASHA256",
+	10: "RSASHA512",
+	12: "ECC-GOST",
+	13: "ECDSAP256SHA256",
+	14: "ECDSAP384SHA384",
+	15: "ED25519",
+	16: "ED448",
+	17: "SM2SM3",
+	23: "ECC-GOST12",
+}
+
+// dsDigestTypes are the DS digest algorithms from the IANA "Delegation
+// Signer (DS) Resource Record Digest Algorithms" registry
+var dsDigestTypes = map[int]string{
+	1: "SHA-1",
+	2: "SHA-256",
+	3: "GOST R 34.11-94",
+	4: "SHA-384",
+	5: "GOST R 34.11-2012",
+	6: "SM3",
+}
+
+// convertSecureDNS converts the secureDNS member, naming algorithms and
+// digest types
+func convertSecureDNS(secureDNS *RDAPSecureDNS) *types.SecureDNS {
+	converted := &types.SecureDNS{
+		DelegationSigned: secureDNS.DelegationSigned,
+		ZoneSigned:       secureDNS.ZoneSigned,
+		MaxSigLife:       secureDNS.MaxSigLife,
+	}
+
+	for _, ds := range secureDNS.DSData {
+		converted.DSData = append(converted.DSData, types.DSRecord{
+			KeyTag:         ds.KeyTag,
+			Algorithm:      ds.Algorithm,
+			AlgorithmName:  dnssecAlgorithms[ds.Algorithm],
+			DigestType:     ds.DigestType,
+			DigestTypeName: dsDigestTypes[ds.DigestType],
+			Digest:         ds.Digest,
+		})
+	}
+
+	for _, key := range secureDNS.KeyData {
+		converted.KeyData = append(converted.KeyData, types.DNSKey{
+			Flags:         key.Flags,
+			Protocol:      key.Protocol,
+			Algorithm:     key.Algorithm,
+			AlgorithmName: dnssecAlgorithms[key.Algorithm],
+			PublicKey:     key.PublicKey,
+		})
+	}
+
+	return converted
+}
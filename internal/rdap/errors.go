@@ -0,0 +1,80 @@
+package rdap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// StatusError is returned when an RDAP server answers with an unexpected
+// HTTP status, including rate limiting (429) that outlasted the retries
+type StatusError struct {
+	Server     string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return fmt.Sprintf("rate limited by RDAP server %s (status 429)", e.Server)
+	}
+	return fmt.Sprintf("RDAP server returned status %d", e.StatusCode)
+}
+
+// RequestError is returned when an RDAP server can't be reached or its
+// response can't be read
+type RequestError struct {
+	Server string
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the request timed out
+func (e *RequestError) Timeout() bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(e.Err, &timeout) && timeout.Timeout()
+}
+
+// NotFoundError is returned when an RDAP object such as a nameserver or
+// network doesn't exist. Domains that don't exist are reported as available
+// instead.
+type NotFoundError struct {
+	Server string
+	Kind   string
+	Name   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no %s found for %s", e.Kind, e.Name)
+}
+
+// QueryError is returned for malformed queries, such as an invalid IP
+// address or search
+type QueryError struct {
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+// invalidQuery returns a QueryError with a formatted message
+func invalidQuery(format string, args ...interface{}) error {
+	return &QueryError{Message: fmt.Sprintf(format, args...)}
+}
+
+// serverHost returns the host of an RDAP server or query URL
+func serverHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
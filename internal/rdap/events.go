@@ -0,0 +1,54 @@
+package rdap
+
+import (
+	"sort"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// convertEvents converts RDAP events to the common format, keeping every
+// action rather than just the registration, expiration and last changed
+// dates
+func convertEvents(events []RDAPEvent) []types.Event {
+	var converted []types.Event
+	for _, event := range events {
+		if event.EventAction == "" {
+			continue
+		}
+		converted = append(converted, types.Event{
+			Action: event.EventAction,
+			Date:   event.EventDate,
+			Actor:  event.EventActor,
+		})
+	}
+	return converted
+}
+
+// mergeEvents adds the events from other whose action isn't in events. When
+// both have an action, such as expiration, the one in events is kept even if
+// the dates differ.
+func mergeEvents(events, other []types.Event) []types.Event {
+	actions := make(map[string]bool, len(events))
+	for _, event := range events {
+		actions[event.Action] = true
+	}
+
+	for _, event := range other {
+		if !actions[event.Action] {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// sortEvents orders normalized events oldest first. Events whose date
+// couldn't be parsed keep their order and go last.
+func sortEvents(events []types.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].Time, events[j].Time
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+}
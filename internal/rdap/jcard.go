@@ -0,0 +1,191 @@
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// jCardProperty is a single jCard (RFC 7095) property: a name, parameters,
+// a value type and one or more values
+type jCardProperty struct {
+	Name   string
+	Params map[string]interface{}
+	Type   string
+	Values []interface{}
+}
+
+// decodeJCard decodes an RDAP vcardArray: ["vcard", [property, ...]]
+func decodeJCard(raw json.RawMessage) ([]jCardProperty, error) {
+	var card []json.RawMessage
+	if err := json.Unmarshal(raw, &card); err != nil {
+		return nil, fmt.Errorf("invalid jCard: %v", err)
+	}
+
+	var tag string
+	if len(card) < 2 || json.Unmarshal(card[0], &tag) != nil || tag != "vcard" {
+		return nil, fmt.Errorf("invalid jCard: not a vcard")
+	}
+
+	var items [][]interface{}
+	if err := json.Unmarshal(card[1], &items); err != nil {
+		return nil, fmt.Errorf("invalid jCard properties: %v", err)
+	}
+
+	var props []jCardProperty
+	for _, item := range items {
+		if len(item) < 4 {
+			continue
+		}
+
+		name, _ := item[0].(string)
+		params, _ := item[1].(map[string]interface{})
+		valueType, _ := item[2].(string)
+
+		props = append(props, jCardProperty{
+			Name:   strings.ToLower(name),
+			Params: params,
+			Type:   valueType,
+			Values: item[3:],
+		})
+	}
+
+	return props, nil
+}
+
+// contactFromEntity decodes an entity's vCard into a contact
+func contactFromEntity(entity RDAPEntity) types.Contact {
+	contact := types.Contact{
+		Handle: entity.Handle,
+		Roles:  entity.Roles,
+	}
+
+	if len(entity.VCardArray) == 0 {
+		return contact
+	}
+	props, err := decodeJCard(entity.VCardArray)
+	if err != nil {
+		return contact
+	}
+
+	for _, prop := range props {
+		switch prop.Name {
+		case "fn":
+			contact.Name = prop.text()
+		case "kind":
+			contact.Kind = prop.text()
+		case "org":
+			contact.Organization = prop.text()
+		case "email":
+			if contact.Email == "" {
+				contact.Email = prop.text()
+			}
+		case "tel":
+			number := strings.TrimPrefix(prop.text(), "tel:")
+			if prop.hasType("fax") {
+				if contact.Fax == "" {
+					contact.Fax = number
+				}
+			} else if contact.Phone == "" {
+				contact.Phone = number
+			}
+		case "adr":
+			if contact.Address == "" {
+				contact.Address, contact.Country = prop.address()
+			}
+		}
+	}
+
+	return contact
+}
+
+// collectContacts decodes the contacts of a list of entities and the
+// entities nested in them, such as a registrar's abuse contact. Nested
+// contacts record their parent's roles.
+func collectContacts(entities []RDAPEntity, parent []string) []types.Contact {
+	var contacts []types.Contact
+	for _, entity := range entities {
+		contact := contactFromEntity(entity)
+		contact.Parent = strings.Join(parent, ",")
+		contacts = append(contacts, contact)
+
+		contacts = append(contacts, collectContacts(entity.Entities, entity.Roles)...)
+	}
+	return contacts
+}
+
+// text returns a property's value as text, joining structured values
+// (such as an org with units) with ", "
+func (p jCardProperty) text() string {
+	var parts []string
+	for _, value := range p.Values {
+		parts = append(parts, flattenValue(value)...)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hasType reports whether the property's "type" parameter includes t
+func (p jCardProperty) hasType(t string) bool {
+	switch v := p.Params["type"].(type) {
+	case string:
+		return strings.EqualFold(v, t)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.EqualFold(s, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// address returns an adr property as a single line and its country. The
+// "label" parameter, when present, is the formatted address; the "cc"
+// parameter (RFC 8605) is the ISO country code.
+func (p jCardProperty) address() (string, string) {
+	var components []interface{}
+	if len(p.Values) == 1 {
+		components, _ = p.Values[0].([]interface{})
+	}
+
+	country, _ := p.Params["cc"].(string)
+	if country == "" && len(components) == 7 {
+		country = strings.Join(flattenValue(components[6]), " ")
+	}
+
+	if label, ok := p.Params["label"].(string); ok && strings.TrimSpace(label) != "" {
+		var lines []string
+		for _, line := range strings.Split(label, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, ", "), country
+	}
+
+	var parts []string
+	for _, component := range components {
+		parts = append(parts, flattenValue(component)...)
+	}
+	return strings.Join(parts, ", "), country
+}
+
+// flattenValue returns the non-empty strings in a jCard value, which may be
+// a string or a (nested) list of strings
+func flattenValue(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			parts = append(parts, flattenValue(item)...)
+		}
+		return parts
+	}
+	return nil
+}
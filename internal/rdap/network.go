@@ -0,0 +1,277 @@
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// RDAPIPNetwork represents an RDAP IP network response
+type RDAPIPNetwork struct {
+	ObjectClassName string       `json:"objectClassName"`
+	Handle          string       `json:"handle,omitempty"`
+	StartAddress    string       `json:"startAddress,omitempty"`
+	EndAddress      string       `json:"endAddress,omitempty"`
+	IPVersion       string       `json:"ipVersion,omitempty"`
+	Name            string       `json:"name,omitempty"`
+	Type            string       `json:"type,omitempty"`
+	Country         string       `json:"country,omitempty"`
+	ParentHandle    string       `json:"parentHandle,omitempty"`
+	Status          []string     `json:"status,omitempty"`
+	Events          []RDAPEvent  `json:"events,omitempty"`
+	Entities        []RDAPEntity `json:"entities,omitempty"`
+	CIDRs           []RDAPCIDR   `json:"cidr0_cidrs,omitempty"`
+}
+
+// RDAPCIDR is a prefix from the cidr0 extension
+type RDAPCIDR struct {
+	V4Prefix string `json:"v4prefix,omitempty"`
+	V6Prefix string `json:"v6prefix,omitempty"`
+	Length   int    `json:"length"`
+}
+
+// RDAPAutnum represents an RDAP autonomous system number response
+type RDAPAutnum struct {
+	ObjectClassName string       `json:"objectClassName"`
+	Handle          string       `json:"handle,omitempty"`
+	StartAutnum     uint32       `json:"startAutnum,omitempty"`
+	EndAutnum       uint32       `json:"endAutnum,omitempty"`
+	Name            string       `json:"name,omitempty"`
+	Type            string       `json:"type,omitempty"`
+	Country         string       `json:"country,omitempty"`
+	Status          []string     `json:"status,omitempty"`
+	Events          []RDAPEvent  `json:"events,omitempty"`
+	Entities        []RDAPEntity `json:"entities,omitempty"`
+}
+
+// ParseIPQuery parses an IP address or CIDR prefix
+func ParseIPQuery(query string) (netip.Prefix, error) {
+	query = strings.TrimSpace(query)
+
+	if strings.Contains(query, "/") {
+		prefix, err := netip.ParsePrefix(query)
+		if err != nil {
+			return netip.Prefix{}, invalidQuery("invalid IP prefix: %s", query)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(query)
+	if err != nil {
+		return netip.Prefix{}, invalidQuery("invalid IP address: %s", query)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ParseASNQuery parses an AS number, with or without the "AS" prefix
+func ParseASNQuery(query string) (uint32, error) {
+	number := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(query)), "AS")
+
+	asn, err := strconv.ParseUint(number, 10, 32)
+	if err != nil {
+		return 0, invalidQuery("invalid AS number: %s", query)
+	}
+	return uint32(asn), nil
+}
+
+// LookupIP performs an RDAP lookup for the network containing an IP address
+// or prefix
+func (c *Client) LookupIP(query string) (*types.NetworkResult, error) {
+	prefix, err := ParseIPQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := c.cache.GetIPRDAPServer(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Single addresses are queried without their prefix length
+	path := prefix.String()
+	if prefix.IsSingleIP() {
+		path = prefix.Addr().String()
+	}
+	queryURL := fmt.Sprintf("%sip/%s", serverURL, path)
+
+	body, err := c.getObject(queryURL, "network", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var network RDAPIPNetwork
+	if err := json.Unmarshal(body, &network); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	result := convertNetwork(&network)
+	result.Query = path
+	result.Server = serverURL
+	result.Raw = string(body)
+
+	return result, nil
+}
+
+// LookupASN performs an RDAP lookup for an autonomous system number
+func (c *Client) LookupASN(asn uint32) (*types.NetworkResult, error) {
+	serverURL, err := c.cache.GetASNRDAPServer(asn)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL := fmt.Sprintf("%sautnum/%d", serverURL, asn)
+
+	body, err := c.getObject(queryURL, "autonomous system", fmt.Sprintf("AS%d", asn))
+	if err != nil {
+		return nil, err
+	}
+
+	var autnum RDAPAutnum
+	if err := json.Unmarshal(body, &autnum); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	result := convertAutnum(&autnum)
+	result.Query = fmt.Sprintf("AS%d", asn)
+	result.Server = serverURL
+	result.Raw = string(body)
+
+	return result, nil
+}
+
+// convertNetwork converts an RDAP IP network to the common result format
+func convertNetwork(network *RDAPIPNetwork) *types.NetworkResult {
+	result := &types.NetworkResult{
+		ObjectClass:  "ip network",
+		Handle:       network.Handle,
+		Name:         network.Name,
+		Type:         network.Type,
+		Country:      network.Country,
+		ParentHandle: network.ParentHandle,
+		Status:       network.Status,
+		Contacts:     collectContacts(network.Entities, nil),
+		IPVersion:    network.IPVersion,
+		StartAddress: network.StartAddress,
+		EndAddress:   network.EndAddress,
+	}
+	result.CreationDate, result.LastModified = eventDates(network.Events)
+
+	for _, cidr := range network.CIDRs {
+		prefix := cidr.V4Prefix
+		if prefix == "" {
+			prefix = cidr.V6Prefix
+		}
+		if prefix != "" {
+			result.CIDRs = append(result.CIDRs, fmt.Sprintf("%s/%d", prefix, cidr.Length))
+		}
+	}
+	if len(result.CIDRs) == 0 {
+		result.CIDRs = rangeToCIDRs(network.StartAddress, network.EndAddress)
+	}
+
+	return result
+}
+
+// convertAutnum converts an RDAP autnum to the common result format
+func convertAutnum(autnum *RDAPAutnum) *types.NetworkResult {
+	result := &types.NetworkResult{
+		ObjectClass: "autnum",
+		Handle:      autnum.Handle,
+		Name:        autnum.Name,
+		Type:        autnum.Type,
+		Country:     autnum.Country,
+		Status:      autnum.Status,
+		Contacts:    collectContacts(autnum.Entities, nil),
+		StartAutnum: autnum.StartAutnum,
+		EndAutnum:   autnum.EndAutnum,
+	}
+	result.CreationDate, result.LastModified = eventDates(autnum.Events)
+
+	return result
+}
+
+// getObject fetches an RDAP object, turning a 404 into a "not found" error
+// naming the kind of object
+func (c *Client) getObject(queryURL, kind, name string) ([]byte, error) {
+	if c.verbose {
+		fmt.Printf("Querying: %s\n", queryURL)
+	}
+
+	status, body, err := c.get(queryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == 404 {
+		return nil, &NotFoundError{Server: serverHost(queryURL), Kind: kind, Name: name}
+	}
+	if status != 200 {
+		return nil, &StatusError{Server: serverHost(queryURL), StatusCode: status}
+	}
+
+	return body, nil
+}
+
+// eventDates returns the registration and last changed dates of an object
+func eventDates(events []RDAPEvent) (string, string) {
+	var created, changed string
+	for _, event := range events {
+		switch event.EventAction {
+		case "registration":
+			created = event.EventDate
+		case "last changed":
+			changed = event.EventDate
+		}
+	}
+	return created, changed
+}
+
+// rangeToCIDRs returns the smallest list of prefixes covering an address
+// range, for servers that don't implement the cidr0 extension
+func rangeToCIDRs(start, end string) []string {
+	first, err := netip.ParseAddr(start)
+	if err != nil {
+		return nil
+	}
+	last, err := netip.ParseAddr(end)
+	if err != nil || first.BitLen() != last.BitLen() || last.Less(first) {
+		return nil
+	}
+
+	bits := first.BitLen()
+	lo := new(big.Int).SetBytes(first.AsSlice())
+	hi := new(big.Int).SetBytes(last.AsSlice())
+	one := big.NewInt(1)
+
+	var cidrs []string
+	for lo.Cmp(hi) <= 0 {
+		// Grow the block while it stays aligned and within the range
+		size := 0
+		for size < bits {
+			block := new(big.Int).Lsh(one, uint(size+1))
+			if new(big.Int).Mod(lo, block).Sign() != 0 {
+				break
+			}
+			blockEnd := new(big.Int).Add(lo, block)
+			if blockEnd.Sub(blockEnd, one).Cmp(hi) > 0 {
+				break
+			}
+			size++
+		}
+
+		buf := make([]byte, bits/8)
+		lo.FillBytes(buf)
+		addr, _ := netip.AddrFromSlice(buf)
+		cidrs = append(cidrs, netip.PrefixFrom(addr, bits-size).String())
+
+		lo.Add(lo, new(big.Int).Lsh(one, uint(size)))
+	}
+
+	return cidrs
+}
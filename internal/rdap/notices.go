@@ -0,0 +1,64 @@
+package rdap
+
+import (
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// convertNotices converts RDAP notices or remarks
+func convertNotices(remarks []RDAPRemark) []types.Notice {
+	var notices []types.Notice
+	for _, remark := range remarks {
+		notices = append(notices, types.Notice{
+			Title:       remark.Title,
+			Type:        remark.Type,
+			Description: remark.Description,
+			Links:       convertLinks(remark.Links),
+		})
+	}
+	return notices
+}
+
+// convertLinks converts RDAP links
+func convertLinks(links []RDAPLink) []types.Link {
+	var converted []types.Link
+	for _, link := range links {
+		converted = append(converted, types.Link{
+			Rel:   link.Rel,
+			Href:  link.Href,
+			Title: link.Title,
+			Type:  link.Type,
+		})
+	}
+	return converted
+}
+
+// mergeNotices appends the notices not already in a list
+func mergeNotices(notices, more []types.Notice) []types.Notice {
+	for _, notice := range more {
+		if !containsNotice(notices, notice) {
+			notices = append(notices, notice)
+		}
+	}
+	return notices
+}
+
+func containsNotice(notices []types.Notice, notice types.Notice) bool {
+	for _, n := range notices {
+		if n.Title == notice.Title && n.Type == notice.Type && equalStrings(n.Description, notice.Description) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
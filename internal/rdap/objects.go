@@ -0,0 +1,139 @@
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// LookupNameserver performs an RDAP lookup for a nameserver at the registry
+// of its TLD, returning its addresses (glue records) and status. The TLD's
+// other servers are tried if the preferred one fails.
+func (c *Client) LookupNameserver(host string) (*types.NameserverResult, error) {
+	_, servers, err := c.findServers(host)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, status, body, err := c.getFromServers(servers, "nameserver/"+host)
+	if err != nil {
+		return nil, err
+	}
+	if status == 404 {
+		return nil, &NotFoundError{Server: serverHost(serverURL), Kind: "nameserver", Name: host}
+	}
+	if status != 200 {
+		return nil, &StatusError{Server: serverHost(serverURL), StatusCode: status}
+	}
+
+	var ns RDAPNameserver
+	if err := json.Unmarshal(body, &ns); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	result := &types.NameserverResult{
+		Query:      host,
+		Nameserver: convertNameserver(ns),
+		Contacts:   collectContacts(ns.Entities, nil),
+		Server:     serverURL,
+		Raw:        string(body),
+	}
+	result.CreationDate, result.LastModified = eventDates(ns.Events)
+
+	return result, nil
+}
+
+// LookupEntity performs an RDAP lookup for an entity handle on the given
+// RDAP server. Entity handles aren't covered by the bootstrap files, so the
+// server has to be known.
+func (c *Client) LookupEntity(handle, serverURL string) (*types.EntityResult, error) {
+	if _, err := url.Parse(serverURL); err != nil || !strings.HasPrefix(serverURL, "http") {
+		return nil, invalidQuery("invalid RDAP server URL: %s", serverURL)
+	}
+	if !strings.HasSuffix(serverURL, "/") {
+		serverURL += "/"
+	}
+
+	queryURL := fmt.Sprintf("%sentity/%s", serverURL, url.PathEscape(handle))
+
+	body, err := c.getObject(queryURL, "entity", handle)
+	if err != nil {
+		return nil, err
+	}
+
+	var entity RDAPEntity
+	if err := json.Unmarshal(body, &entity); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	result := &types.EntityResult{
+		Query:    handle,
+		Contact:  contactFromEntity(entity),
+		Status:   entity.Status,
+		Contacts: collectContacts(entity.Entities, entity.Roles),
+		Domains:  linkedDomains(entity.Links),
+		Server:   serverURL,
+		Raw:      string(body),
+	}
+	result.CreationDate, result.LastModified = eventDates(entity.Events)
+
+	for i := range entity.Networks {
+		result.Networks = append(result.Networks, *convertNetwork(&entity.Networks[i]))
+	}
+	for i := range entity.Autnums {
+		result.Autnums = append(result.Autnums, *convertAutnum(&entity.Autnums[i]))
+	}
+
+	return result, nil
+}
+
+// convertNameserver converts an RDAP nameserver to the common format
+func convertNameserver(ns RDAPNameserver) types.Nameserver {
+	unicodeName := ns.UnicodeName
+	if unicodeName == "" {
+		unicodeName = idn.UnicodeName(ns.LDHName)
+	}
+
+	nameserver := types.Nameserver{
+		LDHName:     ns.LDHName,
+		UnicodeName: unicodeName,
+		Handle:      ns.Handle,
+		Status:      ns.Status,
+	}
+	if ns.IPAddresses != nil {
+		nameserver.IPv4 = ns.IPAddresses.V4
+		nameserver.IPv6 = ns.IPAddresses.V6
+	}
+
+	return nameserver
+}
+
+// linkedDomains returns the domains an object links to
+func linkedDomains(links []RDAPLink) []string {
+	var domains []string
+	seen := make(map[string]bool)
+
+	for _, link := range links {
+		u, err := url.Parse(link.Href)
+		if err != nil {
+			continue
+		}
+
+		i := strings.LastIndex(u.Path, "/domain/")
+		if i < 0 {
+			continue
+		}
+
+		domain := strings.ToLower(strings.Trim(u.Path[i+len("/domain/"):], "/"))
+		if domain != "" && !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+
+	return domains
+}
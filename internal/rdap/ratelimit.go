@@ -0,0 +1,183 @@
+package rdap
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the default number of requests per second sent to
+	// a single RDAP server
+	DefaultRateLimit = 5.0
+
+	// DefaultMaxRetries is the default number of retries for throttled or
+	// temporarily unavailable RDAP servers
+	DefaultMaxRetries = 3
+
+	// BaseBackoff is the initial delay between retries
+	BaseBackoff = 500 * time.Millisecond
+
+	// MaxBackoff caps the exponential backoff delay
+	MaxBackoff = 30 * time.Second
+
+	// MaxRetryAfter is the longest Retry-After delay that will be honored;
+	// servers asking us to wait longer are treated as a hard failure
+	MaxRetryAfter = 2 * time.Minute
+)
+
+// hostLimiter hands out one token bucket per host so that concurrent lookups
+// against the same RDAP server share a single request budget
+type hostLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket is a simple token bucket that can also be paused until a
+// point in time, as requested by a server's Retry-After header
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// newHostLimiter creates a limiter allowing rate requests per second per host
+// with the given burst. A rate of zero or less disables limiting.
+func newHostLimiter(rate float64, burst int) *hostLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &hostLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// bucket returns the token bucket for host, creating it if needed
+func (l *hostLimiter) bucket(host string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &tokenBucket{
+			rate:   l.rate,
+			burst:  float64(l.burst),
+			tokens: float64(l.burst),
+			last:   time.Now(),
+		}
+		l.buckets[host] = b
+	}
+	return b
+}
+
+// Wait blocks until a request to host is allowed
+func (l *hostLimiter) Wait(host string) {
+	l.bucket(host).wait()
+}
+
+// Pause stops all requests to host for the given duration
+func (l *hostLimiter) Pause(host string, d time.Duration) {
+	b := l.bucket(host)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// wait blocks until a token is available and consumes it
+func (b *tokenBucket) wait() {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+
+		if now.Before(b.pausedUntil) {
+			delay := b.pausedUntil.Sub(now)
+			b.mu.Unlock()
+			time.Sleep(delay)
+			continue
+		}
+
+		if b.rate <= 0 {
+			b.mu.Unlock()
+			return
+		}
+
+		// Refill tokens for the time elapsed since the last request
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		time.Sleep(delay)
+	}
+}
+
+// isRetryableStatus reports whether an HTTP status is worth retrying
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of
+// seconds or as an HTTP date. It returns zero if the header is missing or
+// invalid.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// backoff returns the delay before the given retry attempt (starting at 0),
+// using exponential backoff with jitter
+func backoff(attempt int) time.Duration {
+	delay := float64(BaseBackoff) * math.Pow(2, float64(attempt))
+	if delay > float64(MaxBackoff) {
+		delay = float64(MaxBackoff)
+	}
+
+	// Pick a random delay between half and the full backoff so that
+	// concurrent workers don't retry in lockstep
+	return time.Duration(delay/2 + rand.Float64()*delay/2)
+}
@@ -0,0 +1,63 @@
+package rdap
+
+import (
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// RDAPRedaction is an entry of the "redacted" member defined by RFC 9537,
+// describing a field the server removed or replaced
+type RDAPRedaction struct {
+	Name            RDAPRedactionText  `json:"name"`
+	PrePath         string             `json:"prePath,omitempty"`
+	PostPath        string             `json:"postPath,omitempty"`
+	ReplacementPath string             `json:"replacementPath,omitempty"`
+	PathLang        string             `json:"pathLang,omitempty"`
+	Method          string             `json:"method,omitempty"`
+	Reason          *RDAPRedactionText `json:"reason,omitempty"`
+}
+
+// RDAPRedactionText is a redacted field name or reason, given either as a
+// registered type or as free text
+type RDAPRedactionText struct {
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// String returns the type, or the description when there is no type
+func (t RDAPRedactionText) String() string {
+	if t.Type != "" {
+		return t.Type
+	}
+	return t.Description
+}
+
+// convertRedactions converts the redactions of an RDAP response
+func convertRedactions(redacted []RDAPRedaction) []types.Redaction {
+	var redactions []types.Redaction
+	for _, r := range redacted {
+		redaction := types.Redaction{
+			Field:  r.Name.String(),
+			Method: r.Method,
+		}
+
+		// Removal is the default method (RFC 9537 section 4.2)
+		if redaction.Method == "" {
+			redaction.Method = "removal"
+		}
+		if r.Reason != nil {
+			redaction.Reason = r.Reason.String()
+		}
+
+		switch {
+		case r.PrePath != "":
+			redaction.Path = r.PrePath
+		case r.PostPath != "":
+			redaction.Path = r.PostPath
+		case r.ReplacementPath != "":
+			redaction.Path = r.ReplacementPath
+		}
+
+		redactions = append(redactions, redaction)
+	}
+	return redactions
+}
@@ -0,0 +1,204 @@
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// Sources of merged parsed data
+const (
+	SourceRegistry  = "registry"
+	SourceRegistrar = "registrar"
+)
+
+// findRegistrarReferral returns the registrar RDAP URL referenced by a
+// registry response, or an empty string if there is none. The referral is a
+// "related" link to another server's domain object.
+func findRegistrarReferral(resp *RDAPResponse, queryURL string) string {
+	self, err := url.Parse(queryURL)
+	if err != nil {
+		return ""
+	}
+
+	for _, link := range resp.Links {
+		if link.Rel != "related" {
+			continue
+		}
+		if link.Type != "" && !strings.Contains(link.Type, "json") {
+			continue
+		}
+
+		u, err := url.Parse(link.Href)
+		if err != nil || !u.IsAbs() {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(u.Path), "/domain/") {
+			continue
+		}
+		if strings.EqualFold(u.Host, self.Host) {
+			continue
+		}
+
+		return link.Href
+	}
+
+	return ""
+}
+
+// followReferral queries the registrar RDAP server and merges its data into
+// the registry result. Failures are not fatal: the registry data is kept.
+func (c *Client) followReferral(result *types.LookupResult, referral string) {
+	if c.verbose {
+		fmt.Printf("Following registrar referral: %s\n", referral)
+	}
+
+	status, body, err := c.get(referral)
+	if err != nil || status != 200 {
+		if c.verbose {
+			if err == nil {
+				err = fmt.Errorf("status %d", status)
+			}
+			fmt.Printf("Registrar referral failed: %v\n", err)
+		}
+		return
+	}
+
+	var registrarResp RDAPResponse
+	if err := json.Unmarshal(body, &registrarResp); err != nil {
+		if c.verbose {
+			fmt.Printf("Failed to parse registrar response: %v\n", err)
+		}
+		return
+	}
+
+	registrar := c.convertToResult(result.Domain, &registrarResp, body)
+	mergeRegistrarData(result.Parsed, registrar.Parsed, &registrarResp)
+
+	result.ReferralURL = referral
+	result.RegistrarRaw = string(body)
+}
+
+// mergeRegistrarData merges registrar data into the registry's parsed data
+// and records the source of every populated field. Registry data wins for
+// fields the registry is authoritative for (dates, status, nameservers);
+// the registrar fills in the rest and provides the registrant.
+func mergeRegistrarData(registry, registrar *types.ParsedData, registrarResp *RDAPResponse) {
+	sources := make(map[string]string)
+
+	// mergeField keeps the registry value unless it is empty or the
+	// registrar is preferred for this field
+	mergeField := func(name string, dst *string, src string, preferRegistrar bool) {
+		switch {
+		case src != "" && (preferRegistrar || *dst == ""):
+			*dst = src
+			sources[name] = SourceRegistrar
+		case *dst != "":
+			sources[name] = SourceRegistry
+		}
+	}
+
+	mergeField("domainName", &registry.DomainName, registrar.DomainName, false)
+	mergeField("unicodeName", &registry.UnicodeName, registrar.UnicodeName, false)
+	mergeField("registrar", &registry.Registrar, registrar.Registrar, false)
+	mergeField("registrant", &registry.Registrant, registrar.Registrant, true)
+	mergeField("creationDate", &registry.CreationDate, registrar.CreationDate, false)
+	mergeField("expirationDate", &registry.ExpirationDate, registrar.ExpirationDate, false)
+	mergeField("lastModified", &registry.LastModified, registrar.LastModified, false)
+	mergeField("dnssec", &registry.DNSSEC, registrar.DNSSEC, false)
+	mergeField("whoisServer", &registry.WhoisServer, registrar.WhoisServer, false)
+
+	// The registrar's own expiry is reported separately
+	for _, event := range registrarResp.Events {
+		if event.EventAction == "registrar expiration" || event.EventAction == "expiration" {
+			registry.RegistrarExpirationDate = event.EventDate
+			sources["registrarExpirationDate"] = SourceRegistrar
+			break
+		}
+	}
+
+	if registry.SecureDNS == nil && registrar.SecureDNS != nil {
+		registry.SecureDNS = registrar.SecureDNS
+	}
+
+	if len(registry.Nameservers) == 0 && len(registrar.Nameservers) > 0 {
+		registry.Nameservers = registrar.Nameservers
+		registry.NameserverDetails = registrar.NameserverDetails
+		sources["nameservers"] = SourceRegistrar
+	} else if len(registry.Nameservers) > 0 {
+		sources["nameservers"] = SourceRegistry
+	}
+
+	if len(registry.Status) == 0 && len(registrar.Status) > 0 {
+		registry.Status = registrar.Status
+		sources["status"] = SourceRegistrar
+	} else if len(registry.Status) > 0 {
+		sources["status"] = SourceRegistry
+	}
+
+	// The registrar usually has the contacts a thin registry leaves out
+	if len(registrar.Contacts) > 0 {
+		registry.Contacts = mergeContacts(registry.Contacts, registrar.Contacts)
+		sources["contacts"] = SourceRegistrar
+	} else if len(registry.Contacts) > 0 {
+		sources["contacts"] = SourceRegistry
+	}
+
+	// Registrant data, and so most redactions, live with the registrar
+	if len(registrar.Redactions) > 0 {
+		seen := make(map[types.Redaction]bool)
+		for _, r := range registry.Redactions {
+			seen[r] = true
+		}
+		for _, r := range registrar.Redactions {
+			if !seen[r] {
+				registry.Redactions = append(registry.Redactions, r)
+				seen[r] = true
+			}
+		}
+		sources["redactions"] = SourceRegistrar
+	} else if len(registry.Redactions) > 0 {
+		sources["redactions"] = SourceRegistry
+	}
+
+	// Both servers' notices apply, such as each one's terms of service.
+	// Conformance stays the registry's.
+	registry.Notices = mergeNotices(registry.Notices, registrar.Notices)
+	registry.Remarks = mergeNotices(registry.Remarks, registrar.Remarks)
+
+	// The registrar adds the events the registry doesn't publish, such as
+	// its own expiration date; the registry's are kept for the others
+	if len(registrar.Events) > 0 {
+		registry.Events = mergeEvents(registry.Events, registrar.Events)
+	}
+
+	registry.Sources = sources
+	registry.NormalizeDates()
+	registry.NormalizeStatus()
+	sortEvents(registry.Events)
+}
+
+// mergeContacts adds the registrar's contacts to the registry's, skipping
+// those the registry already reported
+func mergeContacts(registry, registrar []types.Contact) []types.Contact {
+	key := func(c types.Contact) string {
+		return strings.Join([]string{strings.Join(c.Roles, ","), c.Parent, c.Handle, c.Name, c.Email}, "|")
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range registry {
+		seen[key(c)] = true
+	}
+
+	merged := registry
+	for _, c := range registrar {
+		if !seen[key(c)] {
+			merged = append(merged, c)
+			seen[key(c)] = true
+		}
+	}
+	return merged
+}
@@ -0,0 +1,204 @@
+package rdap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// Searchable RDAP object types and the properties they can be searched by
+// (RFC 9082 section 3.2)
+var searchFields = map[string][]string{
+	"domains":     {"name", "nsLdhName", "nsIp"},
+	"nameservers": {"name", "ip"},
+}
+
+// DefaultSearchMaxPages is how many result pages a search follows by default
+const DefaultSearchMaxPages = 5
+
+// ErrSearchUnsupported is returned when an RDAP server doesn't implement a
+// search
+var ErrSearchUnsupported = errors.New("search not supported")
+
+// SearchQuery is an RDAP search: the object type to search for, the
+// property to match and the value (which may contain a "*" wildcard for
+// names)
+type SearchQuery struct {
+	Object string
+	Field  string
+	Value  string
+}
+
+// String returns the search's path and query, e.g. domains?name=exam*.com.
+// Wildcards are left unescaped for servers that match them literally.
+func (q SearchQuery) String() string {
+	value := strings.Replace(url.QueryEscape(q.Value), "%2A", "*", -1)
+	return q.Object + "?" + q.Field + "=" + value
+}
+
+// Validate checks the search is one RDAP defines
+func (q SearchQuery) Validate() error {
+	fields, ok := searchFields[q.Object]
+	if !ok {
+		return invalidQuery("invalid search object %q (expected domains or nameservers)", q.Object)
+	}
+	for _, field := range fields {
+		if field == q.Field {
+			if strings.TrimSpace(q.Value) == "" {
+				return invalidQuery("empty search value")
+			}
+			return nil
+		}
+	}
+	return invalidQuery("%s can't be searched by %s (expected one of %s)", q.Object, q.Field, strings.Join(fields, ", "))
+}
+
+// rdapSearchResponse is a page of RDAP search results
+type rdapSearchResponse struct {
+	Conformance []string         `json:"rdapConformance,omitempty"`
+	Domains     []RDAPResponse   `json:"domainSearchResults,omitempty"`
+	Nameservers []RDAPNameserver `json:"nameserverSearchResults,omitempty"`
+	Notices     []RDAPRemark     `json:"notices,omitempty"`
+	Paging      *rdapPaging      `json:"paging_metadata,omitempty"`
+	ErrorCode   int              `json:"errorCode,omitempty"`
+	Title       string           `json:"title,omitempty"`
+}
+
+// rdapPaging is the paging metadata of RFC 8977
+type rdapPaging struct {
+	TotalCount int        `json:"totalCount,omitempty"`
+	PageSize   int        `json:"pageSize,omitempty"`
+	PageNumber int        `json:"pageNumber,omitempty"`
+	Links      []RDAPLink `json:"links,omitempty"`
+}
+
+// ServerFor returns the RDAP server responsible for a domain name's TLD
+func (c *Client) ServerFor(name string) (string, error) {
+	_, serverURL, err := c.findServer(name)
+	return serverURL, err
+}
+
+// Search runs an RDAP search on a server, following up to maxPages pages
+// of results. RFC 9082 searches have no rdapConformance identifier, so
+// support is told from how the server answers the search itself.
+func (c *Client) Search(serverURL string, query SearchQuery, maxPages int) (*types.SearchResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(serverURL, "/") {
+		serverURL += "/"
+	}
+	if maxPages < 1 {
+		maxPages = 1
+	}
+
+	result := &types.SearchResult{
+		Query:  query.String(),
+		Object: query.Object,
+		Server: serverURL,
+	}
+
+	next := serverURL + query.String()
+	for next != "" && result.Pages < maxPages {
+		if c.verbose {
+			fmt.Printf("Querying: %s\n", next)
+		}
+
+		status, body, err := c.get(next)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case 200:
+		case 404:
+			// Servers commonly answer a search that matched nothing with
+			// "not found" rather than an empty result set
+			if result.Pages == 0 {
+				return result, nil
+			}
+			return nil, &StatusError{Server: serverHost(serverURL), StatusCode: status}
+		case 400, 403, 405, 422, 501:
+			// Servers that don't implement a search reject it as a bad or
+			// unknown request, or with "not implemented"
+			if result.Pages == 0 {
+				return nil, fmt.Errorf("%w: RDAP server %s rejected %s (status %d)", ErrSearchUnsupported, serverURL, query.Object+"?"+query.Field, status)
+			}
+			return nil, &StatusError{Server: serverHost(serverURL), StatusCode: status}
+		default:
+			return nil, &StatusError{Server: serverHost(serverURL), StatusCode: status}
+		}
+
+		var page rdapSearchResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %v", err)
+		}
+		result.Pages++
+		if result.Conformance == nil {
+			result.Conformance = page.Conformance
+		}
+
+		for _, domain := range page.Domains {
+			unicodeName := domain.UnicodeName
+			if unicodeName == "" {
+				unicodeName = idn.UnicodeName(domain.LDHName)
+			}
+			result.Domains = append(result.Domains, types.DomainSummary{
+				LDHName:     domain.LDHName,
+				UnicodeName: unicodeName,
+				Handle:      domain.Handle,
+				Status:      domain.Status,
+			})
+		}
+		for _, ns := range page.Nameservers {
+			result.Nameservers = append(result.Nameservers, convertNameserver(ns))
+		}
+
+		if reason := truncationNotice(page.Notices); reason != "" {
+			result.Truncated = true
+			result.TruncatedReason = reason
+		}
+
+		next = ""
+		if page.Paging != nil {
+			if page.Paging.TotalCount > 0 {
+				result.TotalCount = page.Paging.TotalCount
+			}
+			for _, link := range page.Paging.Links {
+				if link.Rel == "next" && link.Href != "" {
+					next = link.Href
+				}
+			}
+		}
+	}
+
+	// More pages were available than were fetched
+	if next != "" {
+		result.Truncated = true
+		if result.TruncatedReason == "" {
+			result.TruncatedReason = fmt.Sprintf("stopped after %d pages", result.Pages)
+		}
+	}
+
+	return result, nil
+}
+
+// truncationNotice returns the title of a notice saying the result set was
+// truncated (RFC 9083 section 10.2.1), if any
+func truncationNotice(notices []RDAPRemark) string {
+	for _, notice := range notices {
+		text := strings.ToLower(notice.Type + " " + notice.Title)
+		if strings.Contains(text, "truncated") {
+			if notice.Type != "" {
+				return notice.Type
+			}
+			return notice.Title
+		}
+	}
+	return ""
+}
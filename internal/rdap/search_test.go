@@ -0,0 +1,74 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a client that doesn't touch the cache, rate limit
+// or retry
+func newTestClient() *Client {
+	return &Client{
+		client:    &http.Client{Timeout: RequestTimeout},
+		limiter:   newHostLimiter(0, 1),
+		userAgent: UserAgent,
+	}
+}
+
+// serveStatus starts an RDAP server that answers every request with status
+// and body
+func serveStatus(t *testing.T, status int, body string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/"
+}
+
+var testSearch = SearchQuery{Object: "domains", Field: "name", Value: "exam*.example"}
+
+func TestSearch(t *testing.T) {
+	body := `{"rdapConformance":["rdap_level_0"],"domainSearchResults":[` +
+		`{"objectClassName":"domain","ldhName":"example.example"},` +
+		`{"objectClassName":"domain","ldhName":"exams.example"}]}`
+	server := serveStatus(t, http.StatusOK, body)
+
+	result, err := newTestClient().Search(server, testSearch, 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(result.Domains) != 2 || result.Domains[0].LDHName != "example.example" {
+		t.Errorf("Domains = %+v, want example.example and exams.example", result.Domains)
+	}
+	if result.Pages != 1 || len(result.Conformance) != 1 {
+		t.Errorf("Pages = %d, Conformance = %q", result.Pages, result.Conformance)
+	}
+}
+
+func TestSearchNotFoundIsEmpty(t *testing.T) {
+	server := serveStatus(t, http.StatusNotFound, `{"errorCode":404,"title":"Not Found"}`)
+
+	result, err := newTestClient().Search(server, testSearch, 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(result.Domains) != 0 || len(result.Nameservers) != 0 || result.Truncated {
+		t.Errorf("result = %+v, want an empty result set", result)
+	}
+}
+
+func TestSearchUnsupported(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		server := serveStatus(t, status, "")
+
+		_, err := newTestClient().Search(server, testSearch, 1)
+		if !errors.Is(err, ErrSearchUnsupported) {
+			t.Errorf("status %d: err = %v, want ErrSearchUnsupported", status, err)
+		}
+	}
+}
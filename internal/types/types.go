@@ -1,26 +1,356 @@
 // Package types defines common types used across the CLI
 package types
 
+import (
+	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/dates"
+	"github.com/simplebytes-com/domaindetails-cli/internal/epp"
+)
+
 // LookupResult represents the result of a domain lookup
 type LookupResult struct {
-	Domain    string      `json:"domain"`
-	Available bool        `json:"available"`
-	Method    string      `json:"method"`
-	Message   string      `json:"message,omitempty"`
-	Parsed    *ParsedData `json:"parsed,omitempty"`
-	Raw       string      `json:"raw,omitempty"`
+	Domain        string      `json:"domain"`
+	UnicodeDomain string      `json:"unicodeDomain,omitempty"`
+	Available     bool        `json:"available"`
+	Method        string      `json:"method"`
+	Message       string      `json:"message,omitempty"`
+	Error         string      `json:"error,omitempty"`
+	Parsed        *ParsedData `json:"parsed,omitempty"`
+	Raw           string      `json:"raw,omitempty"`
+	CachedAt      string      `json:"cachedAt,omitempty"`
+
+	// Query and StrippedSubdomain are set when subdomains were stripped
+	// from the input before the lookup
+	Query             string `json:"query,omitempty"`
+	StrippedSubdomain string `json:"strippedSubdomain,omitempty"`
+
+	// ReferralURL and RegistrarRaw are set when a registry RDAP response
+	// referred to the registrar's RDAP server and that referral was followed
+	ReferralURL  string `json:"referralUrl,omitempty"`
+	RegistrarRaw string `json:"registrarRaw,omitempty"`
 }
 
 // ParsedData contains parsed domain registration information
 type ParsedData struct {
-	DomainName     string   `json:"domainName,omitempty"`
-	Registrar      string   `json:"registrar,omitempty"`
-	Registrant     string   `json:"registrant,omitempty"`
-	CreationDate   string   `json:"creationDate,omitempty"`
-	ExpirationDate string   `json:"expirationDate,omitempty"`
-	LastModified   string   `json:"lastModified,omitempty"`
-	Nameservers    []string `json:"nameservers,omitempty"`
-	Status         []string `json:"status,omitempty"`
-	DNSSEC         string   `json:"dnssec,omitempty"`
-	WhoisServer    string   `json:"whoisServer,omitempty"`
+	DomainName        string       `json:"domainName,omitempty"`
+	UnicodeName       string       `json:"unicodeName,omitempty"`
+	Registrar         string       `json:"registrar,omitempty"`
+	Registrant        string       `json:"registrant,omitempty"`
+	CreationDate      string       `json:"creationDate,omitempty"`
+	ExpirationDate    string       `json:"expirationDate,omitempty"`
+	LastModified      string       `json:"lastModified,omitempty"`
+	Nameservers       []string     `json:"nameservers,omitempty"`
+	NameserverDetails []Nameserver `json:"nameserverDetails,omitempty"`
+	Status            []string     `json:"status,omitempty"`
+	DNSSEC            string       `json:"dnssec,omitempty"`
+	WhoisServer       string       `json:"whoisServer,omitempty"`
+
+	// SecureDNS holds the DS and key data behind DNSSEC, when the RDAP
+	// server returned it
+	SecureDNS *SecureDNS `json:"secureDNS,omitempty"`
+
+	// RegistrarExpirationDate is the expiry reported by the registrar, which
+	// can differ from the registry's ExpirationDate
+	RegistrarExpirationDate string `json:"registrarExpirationDate,omitempty"`
+
+	// The dates above as reported by the server, normalized to UTC (RFC 3339
+	// in JSON). They are left out when a date couldn't be parsed.
+	CreationTime            *time.Time `json:"creationTime,omitempty"`
+	ExpirationTime          *time.Time `json:"expirationTime,omitempty"`
+	LastModifiedTime        *time.Time `json:"lastModifiedTime,omitempty"`
+	RegistrarExpirationTime *time.Time `json:"registrarExpirationTime,omitempty"`
+
+	// Events is every event the RDAP server reported, such as transfers and
+	// locks, oldest first. The dates above are kept as shortcuts.
+	Events []Event `json:"events,omitempty"`
+
+	// StatusDetails are the statuses mapped to EPP codes and explained, and
+	// LockPosture summarizes the locks and holds they put on the domain
+	StatusDetails []StatusDetail `json:"statusDetails,omitempty"`
+	LockPosture   *LockPosture   `json:"lockPosture,omitempty"`
+
+	// Contacts are the contacts of every entity in the response, including
+	// nested ones such as the registrar's abuse contact
+	Contacts []Contact `json:"contacts,omitempty"`
+
+	// Redactions lists the fields the server redacted (RFC 9537)
+	Redactions []Redaction `json:"redactions,omitempty"`
+
+	// Notices and Remarks are the notices (such as terms of service) and
+	// remarks of an RDAP response, Conformance the extensions the server
+	// implements and Links the links it returned for the domain
+	Notices     []Notice `json:"notices,omitempty"`
+	Remarks     []Notice `json:"remarks,omitempty"`
+	Conformance []string `json:"rdapConformance,omitempty"`
+	Links       []Link   `json:"links,omitempty"`
+
+	// Sources maps each populated field to where it came from ("registry"
+	// or "registrar") when data from several sources was merged
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// Event is a dated event in a domain's life, such as its registration or
+// a transfer
+type Event struct {
+	Action string `json:"action"`
+	Date   string `json:"date"`
+	Actor  string `json:"actor,omitempty"`
+
+	// Time is Date normalized to UTC, when it could be parsed
+	Time *time.Time `json:"time,omitempty"`
+}
+
+// StatusDetail is a status value as reported, with its EPP code when it is
+// one. Side is "client" (set by the registrar) or "server" (set by the
+// registry) and Category one of lock, hold, pending, period, state or other.
+type StatusDetail struct {
+	Status      string `json:"status"`
+	Code        string `json:"code"`
+	Side        string `json:"side,omitempty"`
+	Category    string `json:"category"`
+	Description string `json:"description,omitempty"`
+}
+
+// LockPosture summarizes a domain's statuses. RegistrarLock is set by
+// clientTransferProhibited, RegistryLock by the registry prohibiting
+// updates, deletion and transfers, and Hold by clientHold or serverHold
+// (the domain is removed from DNS).
+type LockPosture struct {
+	RegistrarLock bool     `json:"registrarLock"`
+	RegistryLock  bool     `json:"registryLock"`
+	Hold          bool     `json:"hold"`
+	Pending       []string `json:"pending,omitempty"`
+}
+
+// Nameserver contains details about a single nameserver. Addresses are
+// only known from RDAP responses that include them (glue records).
+type Nameserver struct {
+	LDHName     string   `json:"ldhName"`
+	UnicodeName string   `json:"unicodeName,omitempty"`
+	Handle      string   `json:"handle,omitempty"`
+	IPv4        []string `json:"ipv4,omitempty"`
+	IPv6        []string `json:"ipv6,omitempty"`
+	Status      []string `json:"status,omitempty"`
+}
+
+// NameserverResult is the result of an RDAP nameserver lookup
+type NameserverResult struct {
+	Query string `json:"query"`
+	Nameserver
+
+	Contacts     []Contact `json:"contacts,omitempty"`
+	CreationDate string    `json:"creationDate,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Server       string    `json:"server,omitempty"`
+	Raw          string    `json:"raw,omitempty"`
+}
+
+// EntityResult is the result of an RDAP entity lookup
+type EntityResult struct {
+	Query   string   `json:"query"`
+	Contact Contact  `json:"contact"`
+	Status  []string `json:"status,omitempty"`
+
+	// Objects linked to the entity: networks and autonomous systems it is
+	// registered for, domains it links to, and its own nested contacts
+	Networks []NetworkResult `json:"networks,omitempty"`
+	Autnums  []NetworkResult `json:"autnums,omitempty"`
+	Domains  []string        `json:"domains,omitempty"`
+	Contacts []Contact       `json:"contacts,omitempty"`
+
+	CreationDate string `json:"creationDate,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Server       string `json:"server,omitempty"`
+	Raw          string `json:"raw,omitempty"`
+}
+
+// SecureDNS contains a domain's DNSSEC delegation data
+type SecureDNS struct {
+	DelegationSigned bool       `json:"delegationSigned"`
+	ZoneSigned       *bool      `json:"zoneSigned,omitempty"`
+	MaxSigLife       int        `json:"maxSigLife,omitempty"`
+	DSData           []DSRecord `json:"dsData,omitempty"`
+	KeyData          []DNSKey   `json:"keyData,omitempty"`
+}
+
+// DSRecord is a DS record at the parent zone
+type DSRecord struct {
+	KeyTag         int    `json:"keyTag"`
+	Algorithm      int    `json:"algorithm"`
+	AlgorithmName  string `json:"algorithmName,omitempty"`
+	DigestType     int    `json:"digestType"`
+	DigestTypeName string `json:"digestTypeName,omitempty"`
+	Digest         string `json:"digest"`
+}
+
+// DNSKey is a DNSKEY record registered for a domain
+type DNSKey struct {
+	Flags         int    `json:"flags"`
+	Protocol      int    `json:"protocol"`
+	Algorithm     int    `json:"algorithm"`
+	AlgorithmName string `json:"algorithmName,omitempty"`
+	PublicKey     string `json:"publicKey"`
+}
+
+// Notice is an RDAP notice or remark
+type Notice struct {
+	Title       string   `json:"title,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Description []string `json:"description,omitempty"`
+	Links       []Link   `json:"links,omitempty"`
+}
+
+// Link is an RDAP link
+type Link struct {
+	Rel   string `json:"rel,omitempty"`
+	Href  string `json:"href"`
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+// Redaction describes a field an RDAP server removed or replaced: the
+// field's name (e.g. "Registrant Name"), the redaction method (removal,
+// emptyValue, partialValue or replacementValue), the reason and the JSONPath
+// of the field in the response
+type Redaction struct {
+	Field  string `json:"field"`
+	Method string `json:"method"`
+	Reason string `json:"reason,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// NetworkResult is the result of an RDAP IP network or autonomous system
+// lookup
+type NetworkResult struct {
+	Query string `json:"query"`
+
+	// ObjectClass is "ip network" or "autnum"
+	ObjectClass string `json:"objectClass"`
+
+	Handle       string    `json:"handle,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Type         string    `json:"type,omitempty"`
+	Country      string    `json:"country,omitempty"`
+	ParentHandle string    `json:"parentHandle,omitempty"`
+	Status       []string  `json:"status,omitempty"`
+	Contacts     []Contact `json:"contacts,omitempty"`
+
+	// IP networks only
+	IPVersion    string   `json:"ipVersion,omitempty"`
+	StartAddress string   `json:"startAddress,omitempty"`
+	EndAddress   string   `json:"endAddress,omitempty"`
+	CIDRs        []string `json:"cidrs,omitempty"`
+
+	// Autonomous systems only
+	StartAutnum uint32 `json:"startAutnum,omitempty"`
+	EndAutnum   uint32 `json:"endAutnum,omitempty"`
+
+	CreationDate string `json:"creationDate,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Server       string `json:"server,omitempty"`
+	Raw          string `json:"raw,omitempty"`
+}
+
+// ErrorResult describes why a command failed. Code is a stable identifier
+// such as "not_found" or "rate_limited"; Method, Server and HTTPStatus are
+// set when the failure came from a query to a server.
+type ErrorResult struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	ExitCode   int    `json:"exitCode"`
+	Method     string `json:"method,omitempty"`
+	Server     string `json:"server,omitempty"`
+	HTTPStatus int    `json:"httpStatus,omitempty"`
+}
+
+// SearchResult is the result of an RDAP search
+type SearchResult struct {
+	// Query is the search path and query, e.g. "domains?name=exam*.com"
+	Query  string `json:"query"`
+	Object string `json:"object"`
+	Server string `json:"server"`
+
+	// Conformance is the rdapConformance list of the first result page
+	Conformance []string `json:"rdapConformance,omitempty"`
+
+	Domains     []DomainSummary `json:"domains,omitempty"`
+	Nameservers []Nameserver    `json:"nameservers,omitempty"`
+
+	// TotalCount is the number of matches the server reported, which can be
+	// more than were returned. Truncated is set when the server truncated
+	// the results or not all pages were fetched.
+	TotalCount      int    `json:"totalCount,omitempty"`
+	Pages           int    `json:"pages"`
+	Truncated       bool   `json:"truncated"`
+	TruncatedReason string `json:"truncatedReason,omitempty"`
+}
+
+// DomainSummary is a domain found by a search
+type DomainSummary struct {
+	LDHName     string   `json:"ldhName"`
+	UnicodeName string   `json:"unicodeName,omitempty"`
+	Handle      string   `json:"handle,omitempty"`
+	Status      []string `json:"status,omitempty"`
+}
+
+// Contact is a contact decoded from an RDAP entity's vCard
+type Contact struct {
+	Roles        []string `json:"roles,omitempty"`
+	Handle       string   `json:"handle,omitempty"`
+	Kind         string   `json:"kind,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Organization string   `json:"organization,omitempty"`
+	Email        string   `json:"email,omitempty"`
+	Phone        string   `json:"phone,omitempty"`
+	Fax          string   `json:"fax,omitempty"`
+	Address      string   `json:"address,omitempty"`
+	Country      string   `json:"country,omitempty"`
+
+	// Parent holds the roles of the entity this contact is nested in, such
+	// as "registrar" for the registrar's abuse contact
+	Parent string `json:"parent,omitempty"`
+}
+
+// NormalizeDates parses the reported date strings into their normalized
+// fields
+func (p *ParsedData) NormalizeDates() {
+	p.CreationTime = dates.Normalize(p.CreationDate)
+	p.ExpirationTime = dates.Normalize(p.ExpirationDate)
+	p.LastModifiedTime = dates.Normalize(p.LastModified)
+	p.RegistrarExpirationTime = dates.Normalize(p.RegistrarExpirationDate)
+	for i := range p.Events {
+		p.Events[i].Time = dates.Normalize(p.Events[i].Date)
+	}
+}
+
+// NormalizeStatus maps the statuses to EPP codes and derives the lock
+// posture from them
+func (p *ParsedData) NormalizeStatus() {
+	p.StatusDetails = nil
+	p.LockPosture = nil
+	if len(p.Status) == 0 {
+		return
+	}
+
+	codes := make(map[string]bool)
+	posture := &LockPosture{}
+	for _, status := range p.Status {
+		detail := StatusDetail{Status: status, Code: epp.Normalize(status), Category: epp.CategoryOther}
+		if info, ok := epp.Lookup(status); ok {
+			detail.Side = info.Side
+			detail.Category = info.Category
+			detail.Description = info.Description
+			codes[info.Code] = true
+			if info.Category == epp.CategoryPending {
+				posture.Pending = append(posture.Pending, info.Code)
+			}
+		}
+		p.StatusDetails = append(p.StatusDetails, detail)
+	}
+
+	posture.RegistrarLock = codes["clientTransferProhibited"]
+	posture.RegistryLock = codes["serverUpdateProhibited"] && codes["serverDeleteProhibited"] && codes["serverTransferProhibited"]
+	posture.Hold = codes["clientHold"] || codes["serverHold"]
+	p.LockPosture = posture
 }
@@ -7,8 +7,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/simplebytes-com/domaindetails-cli/internal/idn"
 	"github.com/simplebytes-com/domaindetails-cli/internal/types"
 )
 
@@ -25,9 +27,10 @@ const (
 
 // Client performs WHOIS lookups via the DomainDetails.com API
 type Client struct {
-	verbose bool
-	client  *http.Client
-	baseURL string
+	verbose   bool
+	client    *http.Client
+	baseURL   string
+	userAgent string
 }
 
 // APIResponse represents the response from the WHOIS API
@@ -58,10 +61,26 @@ func NewClient(verbose bool) *Client {
 		client: &http.Client{
 			Timeout: RequestTimeout,
 		},
-		baseURL: APIBaseURL,
+		baseURL:   APIBaseURL,
+		userAgent: UserAgent,
 	}
 }
 
+// SetBaseURL sets the API endpoint, such as a self-hosted instance
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetTimeout sets the timeout for each API request
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.client.Timeout = timeout
+}
+
+// SetUserAgent sets the User-Agent sent to the API
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
 // Lookup performs a WHOIS lookup for the given domain via the API
 func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
 	// Build API URL
@@ -78,17 +97,17 @@ func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
 	}
 
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("User-Agent", c.userAgent)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, &RequestError{Server: req.URL.Host, Err: fmt.Errorf("request failed: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
+		return nil, &RequestError{Server: req.URL.Host, Err: fmt.Errorf("failed to read response: %w", err)}
 	}
 
 	// Check for errors
@@ -106,10 +125,7 @@ func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
 			Error string `json:"error"`
 		}
 		json.Unmarshal(body, &errResp)
-		if errResp.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResp.Error)
-		}
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		return nil, &StatusError{Server: req.URL.Host, StatusCode: resp.StatusCode, Message: errResp.Error}
 	}
 
 	// Parse response
@@ -119,7 +135,7 @@ func (c *Client) Lookup(domain string) (*types.LookupResult, error) {
 	}
 
 	if apiResp.Error != "" {
-		return nil, fmt.Errorf("API error: %s", apiResp.Error)
+		return nil, &StatusError{Server: req.URL.Host, StatusCode: resp.StatusCode, Message: apiResp.Error}
 	}
 
 	// Convert to common result format
@@ -143,6 +159,9 @@ func (c *Client) convertToResult(domain string, resp *APIResponse) *types.Lookup
 		parsed.Status = resp.ParsedData.Status
 		parsed.DNSSEC = resp.ParsedData.DNSSEC
 		parsed.WhoisServer = resp.ParsedData.WhoisServer
+		addUnicodeNames(parsed)
+		parsed.NormalizeDates()
+		parsed.NormalizeStatus()
 	}
 
 	return &types.LookupResult{
@@ -153,3 +172,19 @@ func (c *Client) convertToResult(domain string, resp *APIResponse) *types.Lookup
 		Raw:       resp.RawData,
 	}
 }
+
+// addUnicodeNames fills in the Unicode forms of the domain and nameserver
+// names of parsed WHOIS data
+func addUnicodeNames(parsed *types.ParsedData) {
+	if parsed.UnicodeName == "" {
+		parsed.UnicodeName = idn.UnicodeName(parsed.DomainName)
+	}
+
+	parsed.NameserverDetails = nil
+	for _, ns := range parsed.Nameservers {
+		parsed.NameserverDetails = append(parsed.NameserverDetails, types.Nameserver{
+			LDHName:     ns,
+			UnicodeName: idn.UnicodeName(ns),
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package whois
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoWhoisServer is returned by native lookups when no WHOIS server is
+// known for a domain's TLD
+var ErrNoWhoisServer = errors.New("no WHOIS server found")
+
+// StatusError is returned when the WHOIS API answers with an error status
+type StatusError struct {
+	Server     string
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("API error: %s", e.Message)
+	}
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+// RequestError is returned when the WHOIS API or a WHOIS server can't be
+// reached or its response can't be read
+type RequestError struct {
+	Server string
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the request timed out
+func (e *RequestError) Timeout() bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(e.Err, &timeout) && timeout.Timeout()
+}
@@ -0,0 +1,431 @@
+package whois
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+	"github.com/simplebytes-com/domaindetails-cli/internal/whoisparser"
+)
+
+const (
+	// IANAWhoisServer is where native lookups start the referral chain
+	IANAWhoisServer = "whois.iana.org"
+
+	// WhoisPort is the standard WHOIS TCP port
+	WhoisPort = "43"
+
+	// MaxReferrals limits how many referrals a native lookup follows
+	MaxReferrals = 4
+
+	// MaxResponseSize caps how much of a WHOIS response is read
+	MaxResponseSize = 1 << 20
+
+	// responseMarker precedes each server's response in raw output
+	responseMarker = "% Response from "
+)
+
+// queryFormats holds the query quirks of servers that don't accept a bare
+// domain name. Servers not listed here are sent the domain as-is.
+var queryFormats = map[string]string{
+	"whois.verisign-grs.com": "domain %s",
+	"whois.denic.de":         "-T dn,ace %s",
+	"whois.jprs.jp":          "%s/e",
+	"whois.dk-hostmaster.dk": "--show-handles %s",
+}
+
+// referralKeys are the WHOIS fields that point at the next server in the
+// chain, in order of preference
+var referralKeys = []string{
+	"refer",
+	"whois",
+	"registrar whois server",
+	"whois server",
+	"referralserver",
+}
+
+// Response is a single WHOIS server response in a referral chain
+type Response struct {
+	Server string
+	Body   string
+}
+
+// NativeClient performs WHOIS lookups directly over TCP port 43, starting at
+// IANA and following referrals to the registry and registrar servers. It is
+// safe for concurrent use, and asks the root server for each TLD's registry
+// server only once.
+type NativeClient struct {
+	verbose    bool
+	timeout    time.Duration
+	rootServer string
+
+	mu         sync.Mutex
+	registries map[string]*registryEntry
+}
+
+// registryEntry is the root server's answer for a TLD, asked for once
+type registryEntry struct {
+	once   sync.Once
+	server string
+	err    error
+}
+
+// NewNativeClient creates a new native WHOIS client
+func NewNativeClient(verbose bool) *NativeClient {
+	return &NativeClient{
+		verbose:    verbose,
+		timeout:    RequestTimeout,
+		rootServer: IANAWhoisServer,
+	}
+}
+
+// SetRootServer sets the server the referral chain starts at. The server may
+// include a port ("127.0.0.1:4343"); port 43 is used otherwise.
+func (c *NativeClient) SetRootServer(server string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rootServer = server
+	c.registries = nil
+}
+
+// SetTimeout sets the timeout for connecting to and reading from each
+// WHOIS server
+func (c *NativeClient) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// Lookup performs a native WHOIS lookup for the given domain
+func (c *NativeClient) Lookup(domain string) (*types.LookupResult, error) {
+	responses, err := c.QueryChain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseResponses(domain, responses), nil
+}
+
+// QueryChain queries the TLD's registry server, as referred to by the root
+// server, and follows referrals from there, returning every response in the
+// order it was received. A failing referral ends the chain without an error
+// as long as the registry answered.
+func (c *NativeClient) QueryChain(domain string) ([]Response, error) {
+	server, err := c.registryServer(domain)
+	if err != nil {
+		return nil, err
+	}
+	if server == "" {
+		return nil, fmt.Errorf("%w for %s", ErrNoWhoisServer, domain)
+	}
+
+	var responses []Response
+	visited := map[string]bool{serverAddr(c.root()): true}
+
+	// The root server's referral to the registry is the first hop
+	for hop := 1; hop <= MaxReferrals && server != ""; hop++ {
+		visited[serverAddr(server)] = true
+
+		if c.verbose {
+			fmt.Printf("Querying WHOIS server: %s\n", server)
+		}
+
+		body, err := c.query(server, domain)
+		if err != nil {
+			if len(responses) == 0 {
+				return nil, err
+			}
+			if c.verbose {
+				fmt.Printf("WHOIS referral to %s failed: %v\n", server, err)
+			}
+			break
+		}
+
+		responses = append(responses, Response{Server: server, Body: body})
+
+		next := findReferral(body)
+		if next == "" || visited[serverAddr(next)] {
+			break
+		}
+		server = next
+	}
+
+	return responses, nil
+}
+
+// registryServer returns the WHOIS server of a domain's TLD, or an empty
+// string if the root server has none. The root server is asked once per
+// TLD: lookups for the same TLD wait for that answer, failed or not, while
+// lookups for other TLDs go ahead.
+func (c *NativeClient) registryServer(domain string) (string, error) {
+	tld := domain
+	if i := strings.LastIndex(domain, "."); i >= 0 {
+		tld = domain[i+1:]
+	}
+
+	c.mu.Lock()
+	if c.registries == nil {
+		c.registries = make(map[string]*registryEntry)
+	}
+	entry, ok := c.registries[tld]
+	if !ok {
+		entry = &registryEntry{}
+		c.registries[tld] = entry
+	}
+	rootServer := c.rootServer
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		if c.verbose {
+			fmt.Printf("Querying WHOIS server: %s\n", rootServer)
+		}
+
+		body, err := c.query(rootServer, domain)
+		if err != nil {
+			entry.err = err
+			return
+		}
+
+		entry.server = findReferral(body)
+		if serverAddr(entry.server) == serverAddr(rootServer) {
+			entry.server = ""
+		}
+	})
+	return entry.server, entry.err
+}
+
+// root returns the server the referral chain starts at
+func (c *NativeClient) root() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rootServer
+}
+
+// query sends a single WHOIS query and returns the raw response
+func (c *NativeClient) query(server, domain string) (string, error) {
+	conn, err := net.DialTimeout("tcp", serverAddr(server), c.timeout)
+	if err != nil {
+		return "", &RequestError{Server: server, Err: fmt.Errorf("failed to connect to %s: %w", server, err)}
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return "", &RequestError{Server: server, Err: fmt.Errorf("failed to set deadline: %w", err)}
+	}
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", formatQuery(server, domain)); err != nil {
+		return "", &RequestError{Server: server, Err: fmt.Errorf("failed to send query to %s: %w", server, err)}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(conn, MaxResponseSize))
+	if err != nil {
+		return "", &RequestError{Server: server, Err: fmt.Errorf("failed to read response from %s: %w", server, err)}
+	}
+
+	return string(body), nil
+}
+
+// formatQuery builds the query string for a server, applying its quirks
+func formatQuery(server, domain string) string {
+	if format, ok := queryFormats[serverKey(server)]; ok {
+		return fmt.Sprintf(format, domain)
+	}
+	return domain
+}
+
+// serverAddr returns the normalized host:port address of a server,
+// defaulting to the standard WHOIS port
+func serverAddr(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return strings.ToLower(server)
+	}
+	return net.JoinHostPort(serverKey(server), WhoisPort)
+}
+
+// serverKey returns the normalized host name of a server address
+func serverKey(server string) string {
+	host := server
+	if h, _, err := net.SplitHostPort(server); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// findReferral returns the next WHOIS server referenced in a response, or
+// an empty string if there is none
+func findReferral(body string) string {
+	fields := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if _, seen := fields[key]; !seen {
+				fields[key] = value
+			}
+		}
+	}
+
+	for _, key := range referralKeys {
+		if server := normalizeReferral(fields[key]); server != "" {
+			return server
+		}
+	}
+
+	return ""
+}
+
+// normalizeReferral cleans up a referral value such as
+// "whois://whois.example.net:43/" into a server address
+func normalizeReferral(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(lower, "whois://"):
+		value = value[len("whois://"):]
+	case strings.Contains(lower, "://"):
+		// rwhois and web URLs can't be queried over port 43
+		return ""
+	}
+
+	value = strings.TrimSuffix(value, "/")
+	if strings.ContainsAny(value, " \t/") {
+		return ""
+	}
+
+	return strings.ToLower(value)
+}
+
+// ParseResponses converts the registry and registrar responses of a
+// referral chain into common result format. The first response, from the
+// registry, decides availability; later (registrar) responses fill in the
+// fields the registry left out and provide the registrant.
+func ParseResponses(domain string, responses []Response) *types.LookupResult {
+	result := &types.LookupResult{
+		Domain: domain,
+		Method: "whois",
+		Raw:    joinResponses(responses),
+	}
+
+	if len(responses) == 0 {
+		result.Available = true
+		result.Message = "No WHOIS response"
+		return result
+	}
+
+	registry := responses[0]
+	if whoisparser.IsAvailable(domain, serverKey(registry.Server), registry.Body) {
+		result.Available = true
+		result.Message = "Domain not found"
+		return result
+	}
+
+	parsed := whoisparser.Parse(domain, serverKey(registry.Server), registry.Body)
+	for _, resp := range responses[1:] {
+		mergeRegistrarData(parsed, whoisparser.Parse(domain, serverKey(resp.Server), resp.Body))
+	}
+
+	if parsed.DomainName == "" {
+		parsed.DomainName = domain
+	}
+	if parsed.WhoisServer == "" && registry.Server != "" {
+		parsed.WhoisServer = serverKey(registry.Server)
+	}
+	addUnicodeNames(parsed)
+	parsed.NormalizeDates()
+	parsed.NormalizeStatus()
+
+	result.Parsed = parsed
+	return result
+}
+
+// mergeRegistrarData fills the fields missing from the registry data with
+// the registrar's, preferring the registrar's registrant
+func mergeRegistrarData(registry, registrar *types.ParsedData) {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+
+	if registrar.Registrant != "" {
+		registry.Registrant = registrar.Registrant
+	}
+	fill(&registry.Registrar, registrar.Registrar)
+	fill(&registry.CreationDate, registrar.CreationDate)
+	fill(&registry.ExpirationDate, registrar.ExpirationDate)
+	fill(&registry.LastModified, registrar.LastModified)
+	fill(&registry.DNSSEC, registrar.DNSSEC)
+
+	if len(registry.Nameservers) == 0 {
+		registry.Nameservers = registrar.Nameservers
+	}
+	if len(registry.Status) == 0 {
+		registry.Status = registrar.Status
+	}
+}
+
+// SplitResponses splits raw output produced by a native lookup back into
+// its per-server responses. Text without response markers is returned as a
+// single response from an unknown server.
+func SplitResponses(raw string) []Response {
+	var responses []Response
+	var current *Response
+	var body strings.Builder
+
+	flush := func() {
+		if current != nil {
+			current.Body = body.String()
+			responses = append(responses, *current)
+		}
+		body.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(raw, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if server, ok := strings.CutPrefix(trimmed, responseMarker); ok {
+			flush()
+			current = &Response{Server: strings.TrimSpace(server)}
+			continue
+		}
+		if current == nil {
+			current = &Response{}
+		}
+		body.WriteString(line)
+	}
+	flush()
+
+	// Drop the empty leading response when the text starts with a marker
+	if len(responses) > 1 && responses[0].Server == "" && strings.TrimSpace(responses[0].Body) == "" {
+		responses = responses[1:]
+	}
+
+	return responses
+}
+
+// joinResponses joins the responses of a referral chain into a single raw
+// string, each prefixed with the server that sent it
+func joinResponses(responses []Response) string {
+	var b strings.Builder
+	for i, resp := range responses {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s%s\n", responseMarker, resp.Server)
+		b.WriteString(strings.TrimRight(resp.Body, "\r\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,417 @@
+package whois
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// standIn is a local TCP stand-in for a WHOIS server
+type standIn struct {
+	addr string
+
+	mu      sync.Mutex
+	queries []string
+}
+
+// newStandIn starts a WHOIS server on a local port that answers each query
+// with respond's result
+func newStandIn(t *testing.T, respond func(query string) string) *standIn {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &standIn{addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				query := strings.TrimRight(line, "\r\n")
+				s.mu.Lock()
+				s.queries = append(s.queries, query)
+				s.mu.Unlock()
+				fmt.Fprint(conn, respond(query))
+			}()
+		}
+	}()
+
+	return s
+}
+
+// Queries returns the queries the stand-in received
+func (s *standIn) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+// reply returns a stand-in response function that always answers body
+func reply(body string) func(string) string {
+	return func(string) string { return body }
+}
+
+// newTestClient returns a native client starting at the given root server
+func newTestClient(root string) *NativeClient {
+	client := NewNativeClient(false)
+	client.SetRootServer(root)
+	client.SetTimeout(2 * time.Second)
+	return client
+}
+
+// servers returns the servers of a referral chain's responses
+func servers(responses []Response) []string {
+	var list []string
+	for _, resp := range responses {
+		list = append(list, resp.Server)
+	}
+	return list
+}
+
+func TestLookupFollowsReferralChain(t *testing.T) {
+	registrar := newStandIn(t, reply(
+		"Domain Name: EXAMPLE.TEST\r\n"+
+			"Registrar: Example Registrar, Inc.\r\n"+
+			"Registrant Organization: Example Org\r\n"))
+	registry := newStandIn(t, reply(
+		"Domain Name: EXAMPLE.TEST\r\n"+
+			"Registrar WHOIS Server: "+registrar.addr+"\r\n"+
+			"Creation Date: 2001-02-03T04:05:06Z\r\n"+
+			"Registry Expiry Date: 2031-02-03T04:05:06Z\r\n"+
+			"Name Server: NS1.EXAMPLE.TEST\r\n"+
+			"Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited\r\n"))
+	root := newStandIn(t, reply("domain: TEST\r\nrefer: "+registry.addr+"\r\n"))
+
+	result, err := newTestClient(root.addr).Lookup("example.test")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	if result.Available {
+		t.Fatal("registered domain reported as available")
+	}
+	parsed := result.Parsed
+	if parsed.Registrar != "Example Registrar, Inc." {
+		t.Errorf("Registrar = %q", parsed.Registrar)
+	}
+	if parsed.Registrant != "Example Org" {
+		t.Errorf("Registrant = %q, want the registrar's", parsed.Registrant)
+	}
+	if parsed.CreationDate != "2001-02-03T04:05:06Z" {
+		t.Errorf("CreationDate = %q", parsed.CreationDate)
+	}
+	if len(parsed.Nameservers) != 1 || parsed.Nameservers[0] != "ns1.example.test" {
+		t.Errorf("Nameservers = %v", parsed.Nameservers)
+	}
+	if !strings.Contains(result.Raw, responseMarker+registry.addr) || !strings.Contains(result.Raw, responseMarker+registrar.addr) {
+		t.Errorf("raw output doesn't mark both responses:\n%s", result.Raw)
+	}
+	if strings.Contains(result.Raw, responseMarker+root.addr) {
+		t.Errorf("raw output includes the root server's referral:\n%s", result.Raw)
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	registry := newStandIn(t, reply("No match for \"NOPE.TEST\".\r\n"))
+	root := newStandIn(t, reply("refer: "+registry.addr+"\r\n"))
+
+	result, err := newTestClient(root.addr).Lookup("nope.test")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if !result.Available {
+		t.Error("unregistered domain not reported as available")
+	}
+}
+
+func TestLookupWithoutRegistryServer(t *testing.T) {
+	root := newStandIn(t, reply("% This query returned 0 objects.\r\n"))
+
+	_, err := newTestClient(root.addr).Lookup("example.invalid")
+	if !errors.Is(err, ErrNoWhoisServer) {
+		t.Fatalf("err = %v, want ErrNoWhoisServer", err)
+	}
+}
+
+func TestLookupRootUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = newTestClient(addr).Lookup("example.test")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Server != addr {
+		t.Fatalf("err = %#v, want a RequestError for %s", err, addr)
+	}
+}
+
+func TestQueryChainCachesRegistryReferral(t *testing.T) {
+	registry := newStandIn(t, reply("Domain Name: X\r\n"))
+	root := newStandIn(t, reply("refer: "+registry.addr+"\r\n"))
+	client := newTestClient(root.addr)
+
+	var wg sync.WaitGroup
+	for _, domain := range []string{"a.test", "b.test", "c.test", "d.test"} {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			if _, err := client.QueryChain(domain); err != nil {
+				t.Errorf("QueryChain(%s): %v", domain, err)
+			}
+		}(domain)
+	}
+	wg.Wait()
+
+	if got := len(root.Queries()); got != 1 {
+		t.Errorf("root server queried %d times for one TLD, want 1", got)
+	}
+	if got := len(registry.Queries()); got != 4 {
+		t.Errorf("registry server queried %d times, want 4", got)
+	}
+
+	if _, err := client.QueryChain("example.other"); err != nil {
+		t.Fatalf("QueryChain: %v", err)
+	}
+	if got := len(root.Queries()); got != 2 {
+		t.Errorf("root server queried %d times for two TLDs, want 2", got)
+	}
+}
+
+func TestQueryChainRootQueryDoesNotBlockOtherTLDs(t *testing.T) {
+	registry := newStandIn(t, reply("Domain Name: X\r\n"))
+	release := make(chan struct{})
+	root := newStandIn(t, func(query string) string {
+		if strings.HasSuffix(query, ".slow") {
+			<-release
+		}
+		return "refer: " + registry.addr + "\r\n"
+	})
+	client := newTestClient(root.addr)
+
+	done := make(chan error)
+	go func() {
+		_, err := client.QueryChain("example.slow")
+		done <- err
+	}()
+
+	// Wait for the slow TLD's root query to be in flight
+	for len(root.Queries()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := client.QueryChain("example.fast"); err != nil {
+		t.Errorf("QueryChain(example.fast): %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("QueryChain(example.slow): %v", err)
+	}
+}
+
+func TestQueryChainRemembersFailedRootQuery(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	root := newStandIn(t, func(string) string {
+		<-release
+		return ""
+	})
+	client := newTestClient(root.addr)
+	client.SetTimeout(100 * time.Millisecond)
+
+	for _, domain := range []string{"a.test", "b.test"} {
+		_, err := client.QueryChain(domain)
+		var reqErr *RequestError
+		if !errors.As(err, &reqErr) || !reqErr.Timeout() {
+			t.Errorf("QueryChain(%s) err = %v, want a timeout", domain, err)
+		}
+	}
+	if got := len(root.Queries()); got != 1 {
+		t.Errorf("root server queried %d times after a failure, want 1", got)
+	}
+}
+
+func TestQueryChainStopsAtLoop(t *testing.T) {
+	var registryAddr string
+	registrar := newStandIn(t, func(string) string {
+		return "Registrar WHOIS Server: " + registryAddr + "\r\n"
+	})
+	registry := newStandIn(t, reply("Registrar WHOIS Server: "+registrar.addr+"\r\n"))
+	registryAddr = registry.addr
+	root := newStandIn(t, reply("refer: "+registry.addr+"\r\n"))
+
+	responses, err := newTestClient(root.addr).QueryChain("example.test")
+	if err != nil {
+		t.Fatalf("QueryChain: %v", err)
+	}
+	want := []string{registry.addr, registrar.addr}
+	if got := servers(responses); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("servers = %v, want %v", got, want)
+	}
+	if got := len(registry.Queries()); got != 1 {
+		t.Errorf("registry server queried %d times, want 1", got)
+	}
+}
+
+func TestQueryChainIgnoresReferralToRoot(t *testing.T) {
+	var rootAddr string
+	registry := newStandIn(t, func(string) string {
+		return "whois: " + rootAddr + "\r\n"
+	})
+	root := newStandIn(t, reply("refer: "+registry.addr+"\r\n"))
+	rootAddr = root.addr
+
+	responses, err := newTestClient(root.addr).QueryChain("example.test")
+	if err != nil {
+		t.Fatalf("QueryChain: %v", err)
+	}
+	if len(responses) != 1 || len(root.Queries()) != 1 {
+		t.Errorf("servers = %v, root queries = %d", servers(responses), len(root.Queries()))
+	}
+}
+
+func TestQueryChainMaxReferrals(t *testing.T) {
+	// Each server in the chain refers to the next one
+	chain := make([]*standIn, MaxReferrals+2)
+	for i := len(chain) - 1; i >= 0; i-- {
+		body := "Domain Name: EXAMPLE.TEST\r\n"
+		if i+1 < len(chain) {
+			body += "Registrar WHOIS Server: " + chain[i+1].addr + "\r\n"
+		}
+		chain[i] = newStandIn(t, reply(body))
+	}
+	root := newStandIn(t, reply("refer: "+chain[0].addr+"\r\n"))
+
+	responses, err := newTestClient(root.addr).QueryChain("example.test")
+	if err != nil {
+		t.Fatalf("QueryChain: %v", err)
+	}
+
+	// The root server's referral counts as the first
+	if len(responses) != MaxReferrals {
+		t.Errorf("got %d responses, want %d", len(responses), MaxReferrals)
+	}
+	if got := len(chain[MaxReferrals].Queries()); got != 0 {
+		t.Errorf("server beyond MaxReferrals queried %d times", got)
+	}
+}
+
+func TestQueryChainFailingReferral(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	dead := ln.Addr().String()
+	ln.Close()
+
+	registry := newStandIn(t, reply("Domain Name: EXAMPLE.TEST\r\nRegistrar WHOIS Server: "+dead+"\r\n"))
+	root := newStandIn(t, reply("refer: "+registry.addr+"\r\n"))
+
+	responses, err := newTestClient(root.addr).QueryChain("example.test")
+	if err != nil {
+		t.Fatalf("QueryChain: %v", err)
+	}
+	if got := servers(responses); len(got) != 1 || got[0] != registry.addr {
+		t.Errorf("servers = %v, want just the registry", got)
+	}
+}
+
+func TestFormatQuery(t *testing.T) {
+	tests := []struct {
+		server string
+		want   string
+	}{
+		{"whois.verisign-grs.com", "domain example.com"},
+		{"WHOIS.VERISIGN-GRS.COM.", "domain example.com"},
+		{"whois.verisign-grs.com:43", "domain example.com"},
+		{"whois.denic.de", "-T dn,ace example.com"},
+		{"whois.jprs.jp", "example.com/e"},
+		{"whois.dk-hostmaster.dk", "--show-handles example.com"},
+		{"whois.nic.uk", "example.com"},
+		{"127.0.0.1:4343", "example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := formatQuery(tt.server, "example.com"); got != tt.want {
+			t.Errorf("formatQuery(%q) = %q, want %q", tt.server, got, tt.want)
+		}
+	}
+}
+
+func TestFormatQuerySentToServer(t *testing.T) {
+	registry := newStandIn(t, reply("Domain Name: EXAMPLE.TEST\r\n"))
+	root := newStandIn(t, reply("refer: "+registry.addr+"\r\n"))
+
+	if _, err := newTestClient(root.addr).QueryChain("example.test"); err != nil {
+		t.Fatalf("QueryChain: %v", err)
+	}
+	for _, s := range []*standIn{root, registry} {
+		if got := s.Queries(); len(got) != 1 || got[0] != "example.test" {
+			t.Errorf("%s received %q, want one bare query", s.addr, got)
+		}
+	}
+}
+
+func TestNormalizeReferral(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"whois.example.net", "whois.example.net"},
+		{"  WHOIS.Example.NET  ", "whois.example.net"},
+		{"whois://whois.example.net", "whois.example.net"},
+		{"whois://whois.example.net:43/", "whois.example.net:43"},
+		{"WHOIS://whois.example.net/", "whois.example.net"},
+		{"whois.example.net:4343", "whois.example.net:4343"},
+		{"rwhois://rwhois.example.net:4321", ""},
+		{"https://www.example.net/whois", ""},
+		{"whois.example.net/path", ""},
+		{"see the registrar", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeReferral(tt.value); got != tt.want {
+			t.Errorf("normalizeReferral(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFindReferral(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"iana refer", "domain: COM\nrefer: whois.verisign-grs.com\nwhois: whois.other.example\n", "whois.verisign-grs.com"},
+		{"registrar server", "Domain Name: EXAMPLE.COM\n   Registrar WHOIS Server: whois.registrar.example\n", "whois.registrar.example"},
+		{"first value wins", "Registrar WHOIS Server: whois.a.example\nRegistrar WHOIS Server: whois.b.example\n", "whois.a.example"},
+		{"empty value skipped", "refer:\nwhois: whois.nic.example\n", "whois.nic.example"},
+		{"url referral", "ReferralServer: rwhois://rwhois.example.net:4321\n", ""},
+		{"none", "Domain Name: EXAMPLE.COM\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := findReferral(tt.body); got != tt.want {
+			t.Errorf("%s: findReferral = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+// Package whoisparser parses raw WHOIS responses into structured data
+package whoisparser
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// Field names used as keys in the alias tables
+const (
+	fieldDomainName     = "domainName"
+	fieldRegistrar      = "registrar"
+	fieldRegistrant     = "registrant"
+	fieldCreationDate   = "creationDate"
+	fieldExpirationDate = "expirationDate"
+	fieldLastModified   = "lastModified"
+	fieldNameservers    = "nameservers"
+	fieldStatus         = "status"
+	fieldDNSSEC         = "dnssec"
+	fieldDNSKey         = "dnskey"
+	fieldWhoisServer    = "whoisServer"
+)
+
+// defaultAliases lists, for every field, the normalized WHOIS keys it can
+// be read from, in order of preference
+var defaultAliases = map[string][]string{
+	fieldDomainName: {
+		"domain name", "domain", "domainname", "domain_name",
+	},
+	fieldRegistrar: {
+		"registrar", "registrar name", "sponsoring registrar",
+		"registrar organization", "registrar organisation",
+	},
+	fieldRegistrant: {
+		"registrant name", "registrant organization", "registrant organisation",
+		"registrant", "registrant contact name", "holder", "owner",
+	},
+	fieldCreationDate: {
+		"creation date", "created", "created on", "created date",
+		"registered on", "registered date", "registration date", "registration time",
+		"domain registration date", "registered", "domain create date",
+	},
+	fieldExpirationDate: {
+		"registry expiry date", "registrar registration expiration date",
+		"expiry date", "expiration date", "expires on", "expires",
+		"expire date", "expiration time", "paid-till", "renewal date",
+		"registry expiration date", "domain expiration date",
+	},
+	fieldLastModified: {
+		"updated date", "last updated", "last modified", "last update",
+		"last-update", "changed", "modified", "updated",
+	},
+	fieldNameservers: {
+		"name server", "nameserver", "nserver", "name servers",
+		"nameservers", "dns",
+	},
+	fieldStatus: {
+		"domain status", "status", "state", "registration status",
+	},
+	fieldDNSSEC: {
+		"dnssec", "dnssec status",
+	},
+	fieldDNSKey: {
+		"signing key", "dnskey", "ds record", "dnssec ds data",
+	},
+	fieldWhoisServer: {
+		"registrar whois server", "whois server", "whois",
+	},
+}
+
+// defaultAvailable are the case-insensitive phrases that mark a "no match"
+// response
+var defaultAvailable = []string{
+	"no match for",
+	"no match",
+	"not found",
+	"no data found",
+	"no entries found",
+	"no object found",
+	"object does not exist",
+	"the queried object does not exist",
+	"no matching record",
+	"nothing found",
+	"is available for registration",
+	"domain is available",
+	"not registered",
+}
+
+// redactedValues are the case-insensitive placeholders registries use for
+// withheld values, which are treated as missing
+var redactedValues = []string{
+	"redacted for privacy",
+	"data redacted",
+	"not disclosed",
+	"redacted",
+}
+
+// statusAvailable are "no match" phrases of registries that echo the domain
+// back in their "no match" response, so they can't be told apart from a
+// registration record by the presence of a domain field
+var statusAvailable = []string{
+	"status: free",
+	"status: available",
+}
+
+// rules holds the parsing rules for a single WHOIS server
+type rules struct {
+	// aliases overrides the default keys of individual fields
+	aliases map[string][]string
+
+	// available lists extra phrases marking a "no match" response
+	available []string
+
+	// fixup adjusts the parsed data after the generic parsing
+	fixup func(*types.ParsedData)
+}
+
+// serverRules holds the rules of servers whose output needs special care
+var serverRules = map[string]rules{
+	"whois.nic.uk": {
+		aliases: map[string][]string{
+			fieldExpirationDate: {"expiry date", "renewal date"},
+			fieldStatus:         {"registration status"},
+		},
+		available: []string{"this domain name has not been registered"},
+		fixup: func(p *types.ParsedData) {
+			p.Registrar = tagPattern.ReplaceAllString(p.Registrar, "")
+		},
+	},
+	"whois.denic.de": {
+		aliases: map[string][]string{
+			fieldLastModified: {"changed"},
+		},
+	},
+	"whois.jprs.jp": {
+		aliases: map[string][]string{
+			fieldRegistrant: {"registrant", "organization"},
+			fieldStatus:     {"status", "state"},
+		},
+		fixup: func(p *types.ParsedData) {
+			// Plain .jp domains only show their expiry in the state:
+			// "Connected (2025/03/31)"
+			if p.ExpirationDate == "" && len(p.Status) > 0 {
+				if m := jprsStatePattern.FindStringSubmatch(p.Status[0]); m != nil {
+					p.ExpirationDate = m[1]
+				}
+			}
+		},
+	},
+	"whois.eu": {
+		aliases: map[string][]string{
+			fieldRegistrar: {"registrar name", "registrar"},
+			// The registrant block only says where to find the data
+			fieldRegistrant: {"registrant organisation", "registrant name"},
+		},
+	},
+	"whois.nic.fr": {
+		aliases: map[string][]string{
+			fieldLastModified: {"last-update", "changed"},
+		},
+	},
+	"whois.dns.pl": {
+		aliases: map[string][]string{
+			fieldDomainName: {"domain name"},
+		},
+		available: []string{"no information available about domain name"},
+	},
+}
+
+// tldServers maps TLDs to their registry WHOIS server, so the right rules
+// are picked when only the domain is known
+var tldServers = map[string]string{
+	"uk": "whois.nic.uk",
+	"de": "whois.denic.de",
+	"jp": "whois.jprs.jp",
+	"eu": "whois.eu",
+	"fr": "whois.nic.fr",
+	"pl": "whois.dns.pl",
+}
+
+var (
+	// jprsPattern matches JPRS style lines: "a. [Domain Name]  EXAMPLE.JP"
+	jprsPattern = regexp.MustCompile(`^(?:[a-z]\.\s*)?\[([^\]]+)\]\s*(.*)$`)
+
+	// jprsStatePattern matches the date in a JPRS state: "Connected (2025/03/31)"
+	jprsStatePattern = regexp.MustCompile(`\((\d{4}/\d{2}/\d{2})\)`)
+
+	// tagPattern matches Nominet registrar tags: "[Tag = EXAMPLE]"
+	tagPattern = regexp.MustCompile(`\s*\[Tag = [^\]]*\]`)
+)
+
+// Parse parses a raw WHOIS response into ParsedData. The server selects any
+// server-specific rules; when it is empty the domain's TLD is used instead.
+func Parse(domain, server, raw string) *types.ParsedData {
+	r := rulesFor(domain, server)
+	fields := extractFields(raw)
+
+	get := func(field string) string {
+		for _, key := range aliasesFor(r, field) {
+			for _, value := range fields[key] {
+				if value != "" && !isRedacted(value) {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+
+	parsed := &types.ParsedData{
+		DomainName:     strings.ToLower(get(fieldDomainName)),
+		Registrar:      get(fieldRegistrar),
+		Registrant:     get(fieldRegistrant),
+		CreationDate:   get(fieldCreationDate),
+		ExpirationDate: get(fieldExpirationDate),
+		LastModified:   get(fieldLastModified),
+		DNSSEC:         normalizeDNSSEC(get(fieldDNSSEC)),
+		WhoisServer:    strings.ToLower(get(fieldWhoisServer)),
+	}
+
+	// Key material without a DNSSEC field means the domain is signed
+	if parsed.DNSSEC == "" && get(fieldDNSKey) != "" {
+		parsed.DNSSEC = "signed"
+	}
+
+	parsed.Nameservers = collectNameservers(fields, aliasesFor(r, fieldNameservers))
+	parsed.Status = collectValues(fields, aliasesFor(r, fieldStatus))
+
+	if r.fixup != nil {
+		r.fixup(parsed)
+	}
+
+	return parsed
+}
+
+// IsAvailable reports whether a raw WHOIS response says the domain is not
+// registered
+func IsAvailable(domain, server, raw string) bool {
+	lower := strings.ToLower(raw)
+	if containsAny(lower, rulesFor(domain, server).available) || containsAny(lower, statusAvailable) {
+		return true
+	}
+
+	// A response that names the domain is a registration record, even if
+	// its legal boilerplate happens to contain a "not found" phrase
+	fields := extractFields(raw)
+	for _, key := range defaultAliases[fieldDomainName] {
+		if len(fields[key]) > 0 {
+			return false
+		}
+	}
+
+	return containsAny(lower, defaultAvailable)
+}
+
+// isRedacted reports whether a value is a placeholder for withheld data
+func isRedacted(value string) bool {
+	lower := strings.ToLower(strings.TrimRight(strings.TrimSpace(value), "!."))
+	for _, placeholder := range redactedValues {
+		if lower == placeholder || strings.HasPrefix(lower, placeholder+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether s contains any of the patterns
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesFor returns the rules for a server, falling back to the registry
+// server of the domain's TLD
+func rulesFor(domain, server string) rules {
+	server = strings.ToLower(strings.TrimSpace(server))
+	if host, _, ok := strings.Cut(server, ":"); ok {
+		server = host
+	}
+
+	if r, ok := serverRules[server]; ok {
+		return r
+	}
+
+	if i := strings.LastIndex(domain, "."); i >= 0 {
+		if s, ok := tldServers[strings.ToLower(domain[i+1:])]; ok {
+			return serverRules[s]
+		}
+	}
+
+	return rules{}
+}
+
+// aliasesFor returns the keys a field is read from under the given rules
+func aliasesFor(r rules, field string) []string {
+	if aliases, ok := r.aliases[field]; ok {
+		return aliases
+	}
+	return defaultAliases[field]
+}
+
+// extractFields splits a raw response into values keyed by normalized key.
+// It understands plain "Key: value" lines, JPRS "[Key] value" lines, values
+// continued on indented lines below a "Key: value" line, and blocks where
+// values follow a "Key:" line on their own lines, indented or not (NASK).
+// Keys nested in a block are also stored prefixed with the block's key, so
+// "Name:" inside a "Registrar:" block is available as "registrar name".
+func extractFields(raw string) map[string][]string {
+	fields := make(map[string][]string)
+	add := func(key, value string) {
+		fields[key] = append(fields[key], value)
+	}
+
+	var (
+		blockKey    string
+		blockIndent int
+
+		// The last "Key: value" line outside a block, for continuations
+		lastKey    string
+		lastIndent int
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		// Everything after the Verisign style ">>> Last update" marker is
+		// legal boilerplate
+		if strings.HasPrefix(trimmed, ">>>") {
+			break
+		}
+
+		if trimmed == "" {
+			blockKey, lastKey = "", ""
+			continue
+		}
+		if strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if m := jprsPattern.FindStringSubmatch(trimmed); m != nil {
+			add(normalizeKey(m[1]), strings.TrimSpace(m[2]))
+			continue
+		}
+
+		key, value, ok := splitKeyValue(trimmed)
+
+		inBlock := blockKey != "" && (indent > blockIndent || (!ok && indent == blockIndent))
+		if !inBlock {
+			blockKey = ""
+		}
+
+		if !ok {
+			if inBlock {
+				add(blockKey, trimmed)
+			} else if lastKey != "" && indent > lastIndent {
+				add(lastKey, trimmed)
+			}
+			continue
+		}
+
+		if inBlock {
+			add(blockKey+" "+key, value)
+			add(key, value)
+			continue
+		}
+
+		if value == "" {
+			blockKey = key
+			blockIndent = indent
+			lastKey = ""
+			continue
+		}
+
+		add(key, value)
+		lastKey, lastIndent = key, indent
+	}
+
+	return fields
+}
+
+// splitKeyValue splits a "Key: value" line, rejecting lines where the part
+// before the colon doesn't look like a key (URLs, IPv6 addresses, ...)
+func splitKeyValue(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+
+	key = normalizeKey(key)
+	if key == "" || len(key) > 60 || strings.ContainsAny(key, "./@") {
+		return "", "", false
+	}
+	if key == "http" || key == "https" {
+		return "", "", false
+	}
+
+	return key, strings.TrimSpace(value), true
+}
+
+// normalizeKey lowercases a key, drops padding dots ("Domain name.....")
+// and collapses whitespace and underscores
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	key = strings.TrimRight(key, ". ")
+	key = strings.ReplaceAll(key, "_", " ")
+	return strings.ToLower(strings.Join(strings.Fields(key), " "))
+}
+
+// collectValues returns the unique non-empty values of the first key that
+// has any
+func collectValues(fields map[string][]string, keys []string) []string {
+	for _, key := range keys {
+		var values []string
+		seen := make(map[string]bool)
+		for _, value := range fields[key] {
+			if value != "" && !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+		if len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// collectNameservers returns the unique nameserver host names, dropping any
+// glue addresses listed on the same line
+func collectNameservers(fields map[string][]string, keys []string) []string {
+	var nameservers []string
+	for _, value := range collectValues(fields, keys) {
+		parts := strings.Fields(value)
+		if len(parts) == 0 {
+			continue
+		}
+		ns := strings.ToLower(strings.TrimSuffix(parts[0], "."))
+		if !contains(nameservers, ns) {
+			nameservers = append(nameservers, ns)
+		}
+	}
+	return nameservers
+}
+
+// normalizeDNSSEC maps the many DNSSEC spellings onto "signed" and
+// "unsigned", as used by RDAP results
+func normalizeDNSSEC(value string) string {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case lower == "":
+		return ""
+	case strings.Contains(lower, "unsigned"), lower == "no", lower == "inactive", lower == "false":
+		return "unsigned"
+	case strings.Contains(lower, "signed"), lower == "yes", lower == "active", lower == "true":
+		return "signed"
+	}
+	return value
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package whoisparser
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/simplebytes-com/domaindetails-cli/internal/types"
+)
+
+// update rewrites the golden files from the parser's current output:
+// go test ./internal/whoisparser -update
+var update = flag.Bool("update", false, "update golden files")
+
+// corpus lists the anonymized responses in testdata. Registered responses
+// have a .golden.json file with the expected ParsedData.
+var corpus = []struct {
+	name      string
+	domain    string
+	server    string
+	available bool
+}{
+	{"verisign-registered", "example.com", "whois.verisign-grs.com", false},
+	{"verisign-available", "nonexistent-example.com", "whois.verisign-grs.com", true},
+	{"pir-registered", "example.org", "whois.publicinterestregistry.org", false},
+	{"pir-available", "nonexistent-example.org", "whois.publicinterestregistry.org", true},
+	{"nominet-registered", "example.co.uk", "whois.nic.uk", false},
+	{"nominet-available", "nonexistent-example.co.uk", "whois.nic.uk", true},
+	{"denic-registered", "example.de", "whois.denic.de", false},
+	{"denic-available", "nonexistent-example.de", "whois.denic.de", true},
+	{"jprs-registered", "example.co.jp", "whois.jprs.jp", false},
+	{"jprs-available", "nonexistent-example.jp", "whois.jprs.jp", true},
+	{"eurid-registered", "example.eu", "whois.eu", false},
+	{"eurid-available", "nonexistent-example.eu", "whois.eu", true},
+	{"afnic-registered", "example.fr", "whois.nic.fr", false},
+	{"afnic-available", "nonexistent-example.fr", "whois.nic.fr", true},
+	{"nask-registered", "example.pl", "whois.dns.pl", false},
+	{"nask-available", "nonexistent-example.pl", "whois.dns.pl", true},
+}
+
+func readResponse(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name+".txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestIsAvailable(t *testing.T) {
+	for _, tt := range corpus {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := readResponse(t, tt.name)
+			if got := IsAvailable(tt.domain, tt.server, raw); got != tt.available {
+				t.Errorf("IsAvailable = %v, want %v", got, tt.available)
+			}
+			// Without the server, the TLD's rules must give the same answer
+			if got := IsAvailable(tt.domain, "", raw); got != tt.available {
+				t.Errorf("IsAvailable without server = %v, want %v", got, tt.available)
+			}
+		})
+	}
+}
+
+func TestParseGolden(t *testing.T) {
+	for _, tt := range corpus {
+		if tt.available {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.domain, tt.server, readResponse(t, tt.name))
+			golden := filepath.Join("testdata", tt.name+".golden.json")
+
+			if *update {
+				data, err := json.MarshalIndent(got, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, append(data, '\n'), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want types.ParsedData
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatalf("invalid golden file: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, &want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				t.Errorf("Parse output differs from %s:\n%s", golden, gotJSON)
+			}
+		})
+	}
+}
+
+func TestIsAvailableIgnoresBoilerplate(t *testing.T) {
+	// A registration record whose terms mention "not found" is not a
+	// "no match" response
+	raw := "Domain Name: EXAMPLE.COM\nRegistrar: Example Registrar, Inc.\n\n" +
+		"If the requested record is not found, contact the registrar.\n"
+	if IsAvailable("example.com", "whois.verisign-grs.com", raw) {
+		t.Error("registration record reported as available")
+	}
+}
+
+func TestNormalizeDNSSEC(t *testing.T) {
+	tests := map[string]string{
+		"":                 "",
+		"signedDelegation": "signed",
+		"unsigned":         "unsigned",
+		"Unsigned":         "unsigned",
+		"yes":              "signed",
+		"no":               "unsigned",
+		"Inactive":         "unsigned",
+		"something else":   "something else",
+	}
+	for value, want := range tests {
+		if got := normalizeDNSSEC(value); got != want {
+			t.Errorf("normalizeDNSSEC(%q) = %q, want %q", value, got, want)
+		}
+	}
+}